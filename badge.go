@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"att/model"
+)
+
+// badgeKinds are the badges `att badge` can draw, in the order they are
+// regenerated into the data repo.
+var badgeKinds = []string{"streak", "total", "week"}
+
+// badgeDir is where badges are kept in the data repo when they are
+// regenerated on every commit.
+const badgeDir = "badges"
+
+func newBadgeCommand() *command {
+	badgeCmd := newCommand("badge", "<topic>", "Generate an SVG badge for a README")
+	badgeCmd.minArgs, badgeCmd.maxArgs = 1, 1
+	badgeCmd.topicArg = anyTopicArg
+	kind := badgeCmd.flags.String("kind", "streak", "badge to draw: streak, total or week")
+	label := badgeCmd.flags.String("label", "", "left-hand `text` (default: the topic name)")
+	out := badgeCmd.flags.String("out", "", "write to `file` instead of stdout")
+	badgeCmd.examples = []string{
+		"att badge dsa --out streak.svg",
+		"att badge dsa --kind week --label 'DSA this week'",
+		"att config set-badges on   # keep badges/ in the data repo up to date",
+	}
+	badgeCmd.run = func(args []string) error {
+		cfg, err := requireConfig()
+		if err != nil {
+			return err
+		}
+		topicID, err := resolveTopic(cfg.Topics, args[0])
+		if err != nil {
+			return err
+		}
+		data, err := readData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+		svg, err := topicBadge(cfg, data, topicID, *kind, *label, time.Now())
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			fmt.Print(svg)
+			return nil
+		}
+		if err := os.WriteFile(expandHome(*out), []byte(svg), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "✓ Badge written to %s\n", *out)
+		return nil
+	}
+	return badgeCmd
+}
+
+// topicBadge renders one badge for a topic.
+func topicBadge(cfg *model.Config, data *ProgressData, topicID, kind, label string, now time.Time) (string, error) {
+	tc := cfg.Topics[topicID]
+	counts := dailyCounts(data.Topics[topicID])
+	if label == "" {
+		label = tc.Name
+	}
+
+	var value, color string
+	switch kind {
+	case "streak":
+		current, _ := historyStreaks(counts, now)
+		value = pluralDays(current)
+		color = "#4c1"
+		if current == 0 {
+			color = "#9f9f9f"
+		}
+	case "total":
+		total := 0
+		for _, n := range counts {
+			total += n
+		}
+		value = strconv.Itoa(total) + " check-ins"
+		color = "#007ec6"
+	case "week":
+		p, _ := parsePeriod("week", now)
+		elapsed := int(startOfDay(now).Sub(p.start).Hours()/24+0.5) + 1
+		ps := computePeriodStats([]string{topicID}, cfg, map[string]map[string]int{topicID: counts}, p.start, elapsed)
+		pct := int(ps.CompletionRate*100 + 0.5)
+		value = fmt.Sprintf("%d%% this week", pct)
+		color = completionColor(pct)
+	default:
+		return "", usageErrorf("unknown badge kind %q (want %s)", kind, strings.Join(badgeKinds, ", "))
+	}
+	return badgeSVG(label, value, color), nil
+}
+
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// completionColor follows the shields.io scale from red to bright green.
+func completionColor(pct int) string {
+	switch {
+	case pct >= 100:
+		return "#4c1"
+	case pct >= 75:
+		return "#97ca00"
+	case pct >= 50:
+		return "#dfb317"
+	case pct >= 25:
+		return "#fe7d37"
+	default:
+		return "#e05d44"
+	}
+}
+
+// badgeSVG draws a flat shields-style badge. Text width is estimated from
+// terminal cell width, which is close enough for Verdana at 11px.
+func badgeSVG(label, value, color string) string {
+	const cellWidth, padding = 7, 10
+	lw := lipgloss.Width(label)*cellWidth + padding
+	vw := lipgloss.Width(value)*cellWidth + padding
+	label, value = html.EscapeString(label), html.EscapeString(value)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, lw+vw, label, value)
+	fmt.Fprintf(&b, `<title>%s: %s</title>`, label, value)
+	b.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&b, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, lw+vw)
+	fmt.Fprintf(&b, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		lw, lw, vw, color, lw+vw)
+	b.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, lw/2, label, lw/2, label)
+	fmt.Fprintf(&b, `<text x="%d" y="15" fill="#010101" fill-opacity=".3">%s</text><text x="%d" y="14">%s</text>`, lw+vw/2, value, lw+vw/2, value)
+	b.WriteString("</g></svg>\n")
+	return b.String()
+}
+
+// writeBadges regenerates every badge for every enabled topic under
+// badges/ in the data repo, named <topic>-<kind>.svg.
+func writeBadges(dataPath string, cfg *model.Config, data *ProgressData) error {
+	dir := filepath.Join(dataPath, badgeDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return dataErrorf("creating badge directory: %v", err)
+	}
+	now := time.Now()
+	for id, tc := range cfg.Topics {
+		if !tc.Enabled {
+			continue
+		}
+		for _, kind := range badgeKinds {
+			svg, err := topicBadge(cfg, data, id, kind, "", now)
+			if err != nil {
+				return err
+			}
+			name := fmt.Sprintf("%s-%s.svg", topicSlug(id), kind)
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(svg), 0644); err != nil {
+				return dataErrorf("writing badge: %v", err)
+			}
+		}
+	}
+	return nil
+}
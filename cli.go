@@ -0,0 +1,274 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Exit codes. Scripts wrapping att can rely on these staying stable.
+const (
+	exitOK       = 0
+	exitFailure  = 1 // unexpected runtime failure
+	exitUsage    = 2 // bad flags or arguments
+	exitConfig   = 3 // configuration missing or invalid
+	exitNotFound = 4 // referenced topic does not exist
+	exitData     = 5 // data file or git repository problem
+	exitAtRisk   = 6 // att nag found streaks that break at midnight
+	exitProblems = 7 // att doctor found problems
+)
+
+// cliError carries the exit code that main should terminate with.
+type cliError struct {
+	code int
+	err  error
+}
+
+func (e *cliError) Error() string { return e.err.Error() }
+func (e *cliError) Unwrap() error { return e.err }
+
+func usageErrorf(format string, a ...any) error {
+	return &cliError{code: exitUsage, err: fmt.Errorf(format, a...)}
+}
+
+func configErrorf(format string, a ...any) error {
+	return &cliError{code: exitConfig, err: fmt.Errorf(format, a...)}
+}
+
+func notFoundErrorf(format string, a ...any) error {
+	return &cliError{code: exitNotFound, err: fmt.Errorf(format, a...)}
+}
+
+func dataErrorf(format string, a ...any) error {
+	return &cliError{code: exitData, err: fmt.Errorf(format, a...)}
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var ce *cliError
+	if errors.As(err, &ce) {
+		return ce.code
+	}
+	return exitFailure
+}
+
+// command is a node in the att command tree. A command either runs
+// something itself or dispatches to one of its subcommands.
+type command struct {
+	name     string
+	aliases  []string
+	args     string // argument synopsis shown in usage, e.g. "<topic> <remark>"
+	summary  string
+	examples []string
+
+	// minArgs and maxArgs bound the positional arguments accepted by run.
+	// A negative maxArgs means unlimited.
+	minArgs int
+	maxArgs int
+
+	// hidden commands are omitted from help output and completions.
+	hidden bool
+
+	// topicArg selects which topic IDs shell completion offers for the
+	// first positional argument.
+	topicArg int
+
+	// usageFunc replaces the generated usage text when set.
+	usageFunc func(w io.Writer)
+
+	flags       *flag.FlagSet
+	run         func(args []string) error
+	subcommands []*command
+	parent      *command
+}
+
+func newCommand(name, args, summary string) *command {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return &command{
+		name:    name,
+		args:    args,
+		summary: summary,
+		maxArgs: -1,
+		flags:   fs,
+	}
+}
+
+func (c *command) add(subs ...*command) {
+	for _, sub := range subs {
+		sub.parent = c
+		c.subcommands = append(c.subcommands, sub)
+	}
+}
+
+func (c *command) lookup(name string) *command {
+	for _, sub := range c.subcommands {
+		if sub.name == name {
+			return sub
+		}
+		for _, alias := range sub.aliases {
+			if alias == name {
+				return sub
+			}
+		}
+	}
+	return nil
+}
+
+// path returns the full invocation, e.g. "att topic add".
+func (c *command) path() string {
+	if c.parent == nil {
+		return c.name
+	}
+	return c.parent.path() + " " + c.name
+}
+
+func (c *command) execute(args []string) error {
+	var rest []string
+	var err error
+	if len(c.subcommands) > 0 {
+		// Flags must precede the subcommand name so they are not
+		// confused with the subcommand's own flags.
+		err = c.flags.Parse(args)
+		rest = c.flags.Args()
+	} else {
+		rest, err = parseInterspersed(c.flags, args)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		c.printUsage(os.Stdout)
+		return nil
+	}
+	if err != nil {
+		return c.usageError(err.Error())
+	}
+
+	if len(c.subcommands) > 0 && len(rest) > 0 {
+		if sub := c.lookup(rest[0]); sub != nil {
+			return sub.execute(rest[1:])
+		}
+		if c.run == nil {
+			return c.usageError(fmt.Sprintf("unknown %s command: %s", c.path(), rest[0]))
+		}
+	}
+
+	if c.run == nil {
+		c.printUsage(os.Stderr)
+		return &cliError{code: exitUsage, err: errUsagePrinted}
+	}
+
+	if len(rest) < c.minArgs || (c.maxArgs >= 0 && len(rest) > c.maxArgs) {
+		return c.usageError(fmt.Sprintf("wrong number of arguments for '%s'", c.path()))
+	}
+	return c.run(rest)
+}
+
+// errUsagePrinted signals that usage was already written and main should
+// exit without printing anything further.
+var errUsagePrinted = errors.New("usage printed")
+
+// errReported signals that the command already printed its outcome and
+// only the exit code is left to set.
+var errReported = errors.New("outcome reported")
+
+func (c *command) usageError(msg string) error {
+	return usageErrorf("%s\nUsage: %s\nRun '%s --help' for details.", msg, c.synopsis(), c.path())
+}
+
+func (c *command) synopsis() string {
+	s := c.path()
+	if len(c.subcommands) > 0 && c.run == nil {
+		s += " <command>"
+	}
+	if hasFlags(c.flags) {
+		s += " [flags]"
+	}
+	if c.args != "" {
+		s += " " + c.args
+	}
+	return s
+}
+
+func (c *command) printUsage(w io.Writer) {
+	if c.usageFunc != nil {
+		c.usageFunc(w)
+		return
+	}
+	fmt.Fprintf(w, "Usage: %s\n", c.synopsis())
+	if c.summary != "" {
+		fmt.Fprintf(w, "\n%s\n", c.summary)
+	}
+
+	if len(c.subcommands) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		for _, sub := range visibleSubcommands(c) {
+			name := sub.name
+			if sub.args != "" {
+				name += " " + sub.args
+			}
+			fmt.Fprintf(w, "  %-32s %s\n", name, sub.summary)
+		}
+	}
+
+	if hasFlags(c.flags) {
+		fmt.Fprintln(w, "\nFlags:")
+		printFlags(w, c.flags)
+	}
+
+	if len(c.aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases: %s\n", strings.Join(c.aliases, ", "))
+	}
+
+	if len(c.examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+		for _, ex := range c.examples {
+			fmt.Fprintf(w, "  %s\n", ex)
+		}
+	}
+}
+
+func hasFlags(fs *flag.FlagSet) bool {
+	found := false
+	fs.VisitAll(func(*flag.Flag) { found = true })
+	return found
+}
+
+func printFlags(w io.Writer, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		name, usage := flag.UnquoteUsage(f)
+		left := "--" + f.Name
+		if name != "" {
+			left += " " + name
+		}
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+			usage += fmt.Sprintf(" (default %q)", f.DefValue)
+		}
+		fmt.Fprintf(w, "  %-24s %s\n", left, usage)
+	})
+}
+
+// parseInterspersed parses fs allowing flags to appear between positional
+// arguments, so "att checkin dsa 'note' --json" works. Everything after a
+// literal "--" is treated as positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// commitHookMarker identifies post-commit hooks written by att, so they can
+// be replaced or removed without touching anyone else's hook.
+const commitHookMarker = "# Installed by att"
+
+func newHookInstallCommands() (install, uninstall *command) {
+	installCmd := newCommand("install", "<repo>", "Check in whenever you commit to another Git repository")
+	installCmd.minArgs, installCmd.maxArgs = 1, 1
+	topic := installCmd.flags.String("topic", "", "`topic` to check in to (required)")
+	force := installCmd.flags.Bool("force", false, "replace an existing post-commit hook that att did not install")
+	installCmd.examples = []string{
+		"att hook install ~/src/myproject --topic coding",
+	}
+	installCmd.run = func(args []string) error {
+		if *topic == "" {
+			return usageErrorf("--topic is required")
+		}
+		return hookInstall(args[0], *topic, *force)
+	}
+
+	uninstallCmd := newCommand("uninstall", "<repo>", "Remove a post-commit hook installed by att")
+	uninstallCmd.minArgs, uninstallCmd.maxArgs = 1, 1
+	uninstallCmd.run = func(args []string) error { return hookUninstall(args[0]) }
+
+	return installCmd, uninstallCmd
+}
+
+// gitOutput runs git in repoPath and returns its trimmed stdout.
+func gitOutput(repoPath string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", repoPath}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// commitHookPath returns where the post-commit hook of the repository at
+// repo lives, honouring core.hooksPath.
+func commitHookPath(repo string) (string, error) {
+	repo = expandHome(repo)
+	hook, err := gitOutput(repo, "rev-parse", "--git-path", "hooks/post-commit")
+	if err != nil {
+		return "", usageErrorf("%s is not a Git repository: %v", repo, err)
+	}
+	if !filepath.IsAbs(hook) {
+		hook = filepath.Join(repo, hook)
+	}
+	return filepath.Abs(hook)
+}
+
+func isAttCommitHook(path string) (exists, ours bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, false
+	}
+	return true, bytes.Contains(content, []byte(commitHookMarker))
+}
+
+func hookInstall(repo, query string, force bool) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	topicID, err := resolveTopic(cfg.Topics, query)
+	if err != nil {
+		return err
+	}
+	path, err := commitHookPath(repo)
+	if err != nil {
+		return err
+	}
+	if exists, ours := isAttCommitHook(path); exists && !ours && !force {
+		return usageErrorf("%s already exists; pass --force to replace it", path)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		exe = "att"
+	}
+	att := shellQuote(exe)
+	if dir := dataOverride(); dir != "" {
+		att = "ATT_DATA=" + shellQuote(dir) + " " + att
+	}
+	for _, arg := range attArgs() {
+		att += " " + shellQuote(arg)
+	}
+
+	// The check-in runs in the background so a slow sync never holds up the
+	// commit; its output is kept in .git/att-checkin.log. Commits replayed
+	// by a rebase are skipped, since they were checked in the first time.
+	script := fmt.Sprintf(`#!/bin/sh
+%s: check in to %s on every commit.
+# Remove with: att hook uninstall <repo>
+git_dir=$(git rev-parse --git-dir)
+if [ -d "$git_dir/rebase-merge" ] || [ -d "$git_dir/rebase-apply" ]; then
+	exit 0
+fi
+subject=$(git log -1 --format=%%s)
+[ -n "$subject" ] || subject="commit $(git rev-parse --short HEAD)"
+%s checkin -- %s "$subject" </dev/null >"$git_dir/att-checkin.log" 2>&1 &
+`, commitHookMarker, topicID, att, shellQuote(topicID))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return dataErrorf("creating hooks directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return dataErrorf("writing hook: %v", err)
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.Chmod(path, 0755); err != nil {
+		return dataErrorf("writing hook: %v", err)
+	}
+	fmt.Printf("✓ Commits in %s will check in to '%s'\n", expandHome(repo), topicID)
+	fmt.Printf("  Hook: %s\n", path)
+	return nil
+}
+
+func hookUninstall(repo string) error {
+	path, err := commitHookPath(repo)
+	if err != nil {
+		return err
+	}
+	exists, ours := isAttCommitHook(path)
+	if !exists {
+		return notFoundErrorf("%s has no post-commit hook", expandHome(repo))
+	}
+	if !ours {
+		return usageErrorf("%s was not installed by att; remove it by hand", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return dataErrorf("removing hook: %v", err)
+	}
+	fmt.Printf("✓ Removed the att post-commit hook from %s\n", expandHome(repo))
+	return nil
+}
+
+func newScanCommitsCommand() *command {
+	scanCmd := newCommand("scan-commits", "<repo>", "Backfill check-ins from a repository's commit history")
+	scanCmd.minArgs, scanCmd.maxArgs = 1, 1
+	opts := addImportFlags(scanCmd, false)
+	topic := scanCmd.flags.String("topic", "", "`topic` to check in to (required)")
+	author := scanCmd.flags.String("author", "", "only commits whose author matches `pattern` (default: the repo's user.email)")
+	since := scanCmd.flags.String("since", "", "only commits on or after `date`")
+	until := scanCmd.flags.String("until", "", "only commits on or before `date`")
+	all := scanCmd.flags.Bool("all", false, "scan every branch, not just HEAD")
+	scanCmd.examples = []string{
+		"att scan-commits ~/src/myproject --topic coding --dry-run",
+		"att scan-commits . --topic coding --since 2025-01-01 --all",
+		"att scan-commits . --topic coding --author '.'     # every author",
+	}
+	scanCmd.run = func(args []string) error {
+		if *topic == "" {
+			return usageErrorf("--topic is required")
+		}
+		repo := expandHome(args[0])
+		now := time.Now()
+		var from, to time.Time
+		var err error
+		if *since != "" {
+			if from, err = parseDay(*since, now); err != nil {
+				return err
+			}
+		}
+		if *until != "" {
+			if to, err = parseDay(*until, now); err != nil {
+				return err
+			}
+			to = to.AddDate(0, 0, 1)
+		}
+		records, err := readCommitRecords(repo, *topic, *author, *all, from, to)
+		if err != nil {
+			return err
+		}
+		records, err = dropHookCheckins(records)
+		if err != nil {
+			return err
+		}
+		name, _ := filepath.Abs(repo)
+		return runImport(filepath.Base(name), records, opts)
+	}
+	return scanCmd
+}
+
+// readCommitRecords reads one import record per non-merge commit by author,
+// dated by the author date, with the subject as remark.
+func readCommitRecords(repo, query, author string, all bool, from, to time.Time) ([]importRecord, error) {
+	if author == "" {
+		email, err := gitOutput(repo, "config", "user.email")
+		if err != nil || email == "" {
+			return nil, usageErrorf("could not read user.email in %s; pass --author", repo)
+		}
+		author = email
+	}
+	// Match an existing topic by prefix like `att checkin` does, but leave
+	// an unknown one alone so --create-topics can add it.
+	topicID := query
+	if cfg, _ := loadConfig(); cfg != nil {
+		if id, err := resolveTopic(cfg.Topics, query); err == nil {
+			topicID = id
+		}
+	}
+
+	args := []string{"log", "--no-merges", "--format=%aI%x00%s", "--author=" + author}
+	if all {
+		args = append(args, "--all")
+	}
+	out, err := gitOutput(repo, args...)
+	if err != nil {
+		return nil, usageErrorf("reading commits in %s: %v", repo, err)
+	}
+
+	var records []importRecord
+	for _, line := range strings.Split(out, "\n") {
+		date, subject, ok := strings.Cut(line, "\x00")
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return nil, dataErrorf("unexpected commit date %q", date)
+		}
+		if !from.IsZero() && t.Before(from) || !to.IsZero() && !t.Before(to) {
+			continue
+		}
+		if subject == "" {
+			subject = "commit"
+		}
+		records = append(records, importRecord{topicID: topicID, time: t.Local(), remark: subject, amount: 1})
+	}
+	return records, nil
+}
+
+// dropHookCheckins drops commits the post-commit hook already checked in.
+// The hook records the time of the check-in rather than of the commit, so
+// they are matched on topic, day and remark instead.
+func dropHookCheckins(records []importRecord) ([]importRecord, error) {
+	cfg, err := requireConfig()
+	if err != nil {
+		return nil, err
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		return nil, err
+	}
+	recorded := make(map[string]int)
+	key := func(topicID string, t time.Time, remark string) string {
+		return topicID + "\x00" + dayKey(t) + "\x00" + remark
+	}
+	for topicID, topicData := range data.Topics {
+		for _, ci := range topicData.History {
+			if t, err := time.Parse(time.RFC3339, ci.Date); err == nil {
+				recorded[key(topicID, t, ci.Remark)]++
+			}
+		}
+	}
+	kept := records[:0]
+	dropped := 0
+	for _, rec := range records {
+		k := key(rec.topicID, rec.time, rec.remark)
+		if recorded[k] > 0 {
+			recorded[k]--
+			dropped++
+			continue
+		}
+		kept = append(kept, rec)
+	}
+	if dropped > 0 {
+		fmt.Printf("Skipping %d commits already checked in\n", dropped)
+	}
+	return kept, nil
+}
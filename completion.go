@@ -0,0 +1,369 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Which topic IDs a command's first positional argument completes to.
+const (
+	noTopicArg = iota
+	anyTopicArg
+	enabledTopicArg
+)
+
+func newCompletionCommand(root *command) *command {
+	completionCmd := newCommand("completion", "<bash|zsh|fish>", "Print a shell completion script")
+	completionCmd.minArgs, completionCmd.maxArgs = 1, 1
+	completionCmd.examples = []string{
+		"source <(att completion bash)          # add to ~/.bashrc",
+		"att completion zsh > \"${fpath[1]}/_att\"",
+		"att completion fish > ~/.config/fish/completions/att.fish",
+	}
+	completionCmd.run = func(args []string) error {
+		switch args[0] {
+		case "bash":
+			writeBashCompletion(os.Stdout, root)
+		case "zsh":
+			writeZshCompletion(os.Stdout, root)
+		case "fish":
+			writeFishCompletion(os.Stdout, root)
+		default:
+			return usageErrorf("unsupported shell %q: choose bash, zsh or fish", args[0])
+		}
+		return nil
+	}
+
+	// __topics is called by the generated scripts to complete topic IDs.
+	topicsCmd := newCommand("__topics", "", "List topic IDs for shell completion")
+	topicsCmd.hidden = true
+	topicsCmd.maxArgs = 0
+	enabledOnly := topicsCmd.flags.Bool("enabled", false, "only list enabled topics")
+	topicsCmd.run = func([]string) error {
+		cfg, err := loadConfig()
+		if err != nil || cfg == nil {
+			return nil
+		}
+		var ids []string
+		for id, topic := range cfg.Topics {
+			if *enabledOnly && !topic.Enabled {
+				continue
+			}
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+		return nil
+	}
+
+	root.add(topicsCmd)
+	return completionCmd
+}
+
+// completionState identifies a command in the generated scripts,
+// e.g. "att_topic_add".
+func completionState(c *command) string {
+	return strings.ReplaceAll(c.path(), " ", "_")
+}
+
+// walkCommands visits c and every visible descendant, parents first.
+func walkCommands(c *command, fn func(*command)) {
+	fn(c)
+	for _, sub := range c.subcommands {
+		if !sub.hidden {
+			walkCommands(sub, fn)
+		}
+	}
+}
+
+func visibleSubcommands(c *command) []*command {
+	var subs []*command
+	for _, sub := range c.subcommands {
+		if !sub.hidden {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+func commandNames(c *command) []string {
+	return append([]string{c.name}, c.aliases...)
+}
+
+// flagNames returns the flags of c as typed on the command line, with
+// single-letter flags in short form.
+func flagNames(c *command) []string {
+	names := []string{"--help"}
+	c.flags.VisitAll(func(f *flag.Flag) {
+		if len(f.Name) == 1 {
+			names = append(names, "-"+f.Name)
+		} else {
+			names = append(names, "--"+f.Name)
+		}
+	})
+	return names
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// valueFlags returns the flags of every command that consume the next word,
+// except --config and --profile, which the scripts pass on to att __topics.
+func valueFlags(root *command) []string {
+	seen := map[string]bool{"config": true, "profile": true}
+	var names []string
+	walkCommands(root, func(c *command) {
+		c.flags.VisitAll(func(f *flag.Flag) {
+			if !isBoolFlag(f) && !seen[f.Name] {
+				seen[f.Name] = true
+				names = append(names, "--"+f.Name)
+			}
+		})
+	})
+	return names
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeBashCompletion(w io.Writer, root *command) {
+	fmt.Fprintln(w, "# bash completion for att")
+	fmt.Fprintln(w, "_att() {")
+	fmt.Fprintln(w, `    local cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}`)
+	fmt.Fprintln(w, "    local cmd=att npos=0 i w")
+	fmt.Fprintln(w, "    local -a cfg=()")
+	fmt.Fprintln(w, "    for ((i = 1; i < COMP_CWORD; i++)); do")
+	fmt.Fprintln(w, `        w=${COMP_WORDS[i]}`)
+	fmt.Fprintln(w, `        case "$w" in`)
+	fmt.Fprintln(w, `            --config|--profile) cfg+=("$w" "${COMP_WORDS[i+1]}"); ((i++)); continue ;;`)
+	if vf := valueFlags(root); len(vf) > 0 {
+		fmt.Fprintf(w, "            %s) ((i++)); continue ;;\n", strings.Join(vf, "|"))
+	}
+	fmt.Fprintln(w, "            -*) continue ;;")
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, `        case "$cmd:$w" in`)
+	walkCommands(root, func(c *command) {
+		for _, sub := range visibleSubcommands(c) {
+			var patterns []string
+			for _, name := range commandNames(sub) {
+				patterns = append(patterns, shellQuote(completionState(c)+":"+name))
+			}
+			fmt.Fprintf(w, "            %s) cmd=%s ;;\n", strings.Join(patterns, "|"), completionState(sub))
+		}
+	})
+	fmt.Fprintln(w, "            *) ((npos++)) ;;")
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "    done")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    if [[ $prev == --config ]]; then`)
+	fmt.Fprintln(w, `        COMPREPLY=($(compgen -f -- "$cur"))`)
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    if [[ $cur == -* ]]; then`)
+	fmt.Fprintln(w, "        case $cmd in")
+	walkCommands(root, func(c *command) {
+		fmt.Fprintf(w, "            %s) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n",
+			completionState(c), shellQuote(strings.Join(flagNames(c), " ")))
+	})
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "    case $cmd in")
+	walkCommands(root, func(c *command) {
+		state := completionState(c)
+		if subs := visibleSubcommands(c); len(subs) > 0 {
+			var names []string
+			for _, sub := range subs {
+				names = append(names, sub.name)
+			}
+			fmt.Fprintf(w, "        %s) [[ $npos -eq 0 ]] && COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n",
+				state, shellQuote(strings.Join(names, " ")))
+			return
+		}
+		switch c.topicArg {
+		case anyTopicArg:
+			fmt.Fprintf(w, "        %s) [[ $npos -eq 0 ]] && COMPREPLY=($(compgen -W \"$(att \"${cfg[@]}\" __topics 2>/dev/null)\" -- \"$cur\")) ;;\n", state)
+		case enabledTopicArg:
+			fmt.Fprintf(w, "        %s) [[ $npos -eq 0 ]] && COMPREPLY=($(compgen -W \"$(att \"${cfg[@]}\" __topics --enabled 2>/dev/null)\" -- \"$cur\")) ;;\n", state)
+		}
+	})
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -F _att att")
+}
+
+func writeZshCompletion(w io.Writer, root *command) {
+	fmt.Fprintln(w, "#compdef att")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "_att() {")
+	fmt.Fprintln(w, "    local cmd=att npos=0 i w")
+	fmt.Fprintln(w, "    local -a cfg topics")
+	fmt.Fprintln(w, "    for ((i = 2; i < CURRENT; i++)); do")
+	fmt.Fprintln(w, "        w=${words[i]}")
+	fmt.Fprintln(w, `        case "$w" in`)
+	fmt.Fprintln(w, `            --config|--profile) cfg+=("$w" "${words[i+1]}"); ((i++)); continue ;;`)
+	if vf := valueFlags(root); len(vf) > 0 {
+		fmt.Fprintf(w, "            %s) ((i++)); continue ;;\n", strings.Join(vf, "|"))
+	}
+	fmt.Fprintln(w, "            -*) continue ;;")
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, `        case "$cmd:$w" in`)
+	walkCommands(root, func(c *command) {
+		for _, sub := range visibleSubcommands(c) {
+			var patterns []string
+			for _, name := range commandNames(sub) {
+				patterns = append(patterns, shellQuote(completionState(c)+":"+name))
+			}
+			fmt.Fprintf(w, "            %s) cmd=%s ;;\n", strings.Join(patterns, "|"), completionState(sub))
+		}
+	})
+	fmt.Fprintln(w, "            *) ((npos++)) ;;")
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "    done")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    if [[ ${words[CURRENT-1]} == --config ]]; then`)
+	fmt.Fprintln(w, "        _files")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, `    if [[ $PREFIX == -* ]]; then`)
+	fmt.Fprintln(w, "        case $cmd in")
+	walkCommands(root, func(c *command) {
+		fmt.Fprintf(w, "            %s) compadd -- %s ;;\n", completionState(c), strings.Join(flagNames(c), " "))
+	})
+	fmt.Fprintln(w, "        esac")
+	fmt.Fprintln(w, "        return")
+	fmt.Fprintln(w, "    fi")
+	fmt.Fprintln(w, "    case $cmd in")
+	walkCommands(root, func(c *command) {
+		state := completionState(c)
+		if subs := visibleSubcommands(c); len(subs) > 0 {
+			var described []string
+			for _, sub := range subs {
+				described = append(described, shellQuote(strings.ReplaceAll(sub.name, ":", `\:`)+":"+sub.summary))
+			}
+			fmt.Fprintf(w, "        %s)\n", state)
+			fmt.Fprintf(w, "            local -a subcmds=(%s)\n", strings.Join(described, " "))
+			fmt.Fprintln(w, "            (( npos == 0 )) && _describe -t commands 'att command' subcmds")
+			fmt.Fprintln(w, "            ;;")
+			return
+		}
+		switch c.topicArg {
+		case anyTopicArg:
+			fmt.Fprintf(w, "        %s)\n", state)
+			fmt.Fprintln(w, `            topics=(${(f)"$(att "${cfg[@]}" __topics 2>/dev/null)"})`)
+			fmt.Fprintln(w, "            (( npos == 0 )) && compadd -a topics")
+			fmt.Fprintln(w, "            ;;")
+		case enabledTopicArg:
+			fmt.Fprintf(w, "        %s)\n", state)
+			fmt.Fprintln(w, `            topics=(${(f)"$(att "${cfg[@]}" __topics --enabled 2>/dev/null)"})`)
+			fmt.Fprintln(w, "            (( npos == 0 )) && compadd -a topics")
+			fmt.Fprintln(w, "            ;;")
+		}
+	})
+	fmt.Fprintln(w, "    esac")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `if [[ $zsh_eval_context[-1] == loadautofunc ]]; then`)
+	fmt.Fprintln(w, `    _att "$@"`)
+	fmt.Fprintln(w, "else")
+	fmt.Fprintln(w, "    compdef _att att")
+	fmt.Fprintln(w, "fi")
+}
+
+func writeFishCompletion(w io.Writer, root *command) {
+	fmt.Fprintln(w, "# fish completion for att")
+	fmt.Fprintln(w, "function __att_state")
+	fmt.Fprintln(w, "    set -l tokens (commandline -opc)")
+	fmt.Fprintln(w, "    set -l cmd att")
+	fmt.Fprintln(w, "    set -l npos 0")
+	fmt.Fprintln(w, "    set -l skip 0")
+	fmt.Fprintln(w, "    for w in $tokens[2..-1]")
+	fmt.Fprintln(w, "        if test $skip -eq 1")
+	fmt.Fprintln(w, "            set skip 0")
+	fmt.Fprintln(w, "            continue")
+	fmt.Fprintln(w, "        end")
+	fmt.Fprintln(w, "        switch $w")
+	vf := []string{"'--config'", "'--profile'"}
+	for _, f := range valueFlags(root) {
+		vf = append(vf, shellQuote(f))
+	}
+	fmt.Fprintf(w, "            case %s\n", strings.Join(vf, " "))
+	fmt.Fprintln(w, "                set skip 1")
+	fmt.Fprintln(w, "                continue")
+	fmt.Fprintln(w, "            case '-*'")
+	fmt.Fprintln(w, "                continue")
+	fmt.Fprintln(w, "        end")
+	fmt.Fprintln(w, `        switch "$cmd:$w"`)
+	walkCommands(root, func(c *command) {
+		for _, sub := range visibleSubcommands(c) {
+			var patterns []string
+			for _, name := range commandNames(sub) {
+				patterns = append(patterns, shellQuote(completionState(c)+":"+name))
+			}
+			fmt.Fprintf(w, "            case %s\n", strings.Join(patterns, " "))
+			fmt.Fprintf(w, "                set cmd %s\n", completionState(sub))
+		}
+	})
+	fmt.Fprintln(w, "            case '*'")
+	fmt.Fprintln(w, "                set npos (math $npos + 1)")
+	fmt.Fprintln(w, "        end")
+	fmt.Fprintln(w, "    end")
+	fmt.Fprintln(w, "    echo $cmd $npos")
+	fmt.Fprintln(w, "end")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "# __att_at CMD [NPOS] succeeds when the command line is at CMD,")
+	fmt.Fprintln(w, "# optionally with exactly NPOS positional arguments already given.")
+	fmt.Fprintln(w, "function __att_at -a want_cmd want_npos")
+	fmt.Fprintln(w, "    set -l state (string split ' ' (__att_state))")
+	fmt.Fprintln(w, `    test "$state[1]" = "$want_cmd"; or return 1`)
+	fmt.Fprintln(w, `    test -z "$want_npos"; or test "$state[2]" = "$want_npos"`)
+	fmt.Fprintln(w, "end")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "function __att_topics")
+	fmt.Fprintln(w, "    set -l tokens (commandline -opc)")
+	fmt.Fprintln(w, "    set -l cfg")
+	fmt.Fprintln(w, "    for flag in --config --profile")
+	fmt.Fprintln(w, "        set -l i (contains -i -- $flag $tokens)")
+	fmt.Fprintln(w, "        and set -a cfg $flag $tokens[(math $i + 1)]")
+	fmt.Fprintln(w, "    end")
+	fmt.Fprintln(w, "    att $cfg __topics $argv 2>/dev/null")
+	fmt.Fprintln(w, "end")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "complete -c att -f")
+	walkCommands(root, func(c *command) {
+		state := completionState(c)
+		for _, sub := range visibleSubcommands(c) {
+			fmt.Fprintf(w, "complete -c att -n '__att_at %s 0' -a %s -d %s\n",
+				state, shellQuote(sub.name), shellQuote(sub.summary))
+		}
+		c.flags.VisitAll(func(f *flag.Flag) {
+			opt := "-l " + f.Name
+			if len(f.Name) == 1 {
+				opt = "-s " + f.Name
+			}
+			if !isBoolFlag(f) {
+				opt += " -r"
+				if f.Name == "config" {
+					opt += " -F"
+				}
+			}
+			_, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(w, "complete -c att -n '__att_at %s' %s -d %s\n", state, opt, shellQuote(usage))
+		})
+		switch c.topicArg {
+		case anyTopicArg:
+			fmt.Fprintf(w, "complete -c att -n '__att_at %s 0' -a '(__att_topics)'\n", state)
+		case enabledTopicArg:
+			fmt.Fprintf(w, "complete -c att -n '__att_at %s 0' -a '(__att_topics --enabled)'\n", state)
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// startOfDay returns local midnight on t's calendar day.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// parseDay parses a day given on the command line: "today", "yesterday",
+// a relative offset such as "7d" or "2w", or a date like "2025-01-31".
+// The result is local midnight on that day.
+func parseDay(s string, now time.Time) (time.Time, error) {
+	today := startOfDay(now)
+	switch s {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if n, unit, ok := splitRelative(s); ok {
+		switch unit {
+		case "d":
+			return today.AddDate(0, 0, -n), nil
+		case "w":
+			return today.AddDate(0, 0, -7*n), nil
+		case "m":
+			return today.AddDate(0, -n, 0), nil
+		case "y":
+			return today.AddDate(-n, 0, 0), nil
+		}
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return startOfDay(t), nil
+	}
+	return time.Time{}, usageErrorf("invalid date %q: use YYYY-MM-DD, today, yesterday or an offset like 7d", s)
+}
+
+// splitRelative splits "30d" into 30 and "d".
+func splitRelative(s string) (int, string, bool) {
+	if len(s) < 2 {
+		return 0, "", false
+	}
+	unit := strings.ToLower(s[len(s)-1:])
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return 0, "", false
+	}
+	return n, unit, true
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"att/model"
+)
+
+func newDoctorCommand() *command {
+	doctorCmd := newCommand("doctor", "", "Check config, data and Git setup for problems")
+	doctorCmd.maxArgs = 0
+	fix := doctorCmd.flags.Bool("fix", false, "repair what can be repaired automatically")
+	remote := doctorCmd.flags.Bool("remote", false, "also check that the Git remote is reachable")
+	doctorCmd.examples = []string{
+		"att doctor",
+		"att doctor --fix",
+		"att doctor --remote",
+	}
+	doctorCmd.run = func([]string) error { return runDoctor(*fix, *remote) }
+	return doctorCmd
+}
+
+// doctor collects findings. A finding with a fix can be repaired by
+// --fix; the others say what to do by hand.
+type doctor struct {
+	fix      bool
+	problems int
+	warnings int
+	fixable  int
+	fixed    int
+}
+
+func (d *doctor) section(name string) {
+	fmt.Printf("\n%s\n", name)
+}
+
+func (d *doctor) ok(format string, a ...any) {
+	fmt.Printf("  ✓ %s\n", fmt.Sprintf(format, a...))
+}
+
+// report prints a problem (or a warning, if warn is set) with a hint on
+// fixing it by hand. With --fix, fix is applied instead when not nil.
+func (d *doctor) report(warn bool, msg, hint string, fix func() error) {
+	mark := "✗"
+	if warn {
+		mark = "!"
+	}
+	if fix != nil && d.fix {
+		if err := fix(); err != nil {
+			fmt.Printf("  %s %s\n      could not fix: %v\n", mark, msg, err)
+		} else {
+			fmt.Printf("  ✓ %s (fixed)\n", msg)
+			d.fixed++
+			return
+		}
+	} else {
+		fmt.Printf("  %s %s\n", mark, msg)
+		if hint != "" {
+			fmt.Printf("      %s\n", hint)
+		}
+	}
+	if warn {
+		d.warnings++
+	} else {
+		d.problems++
+	}
+	if fix != nil && !d.fix {
+		d.fixable++
+	}
+}
+
+func (d *doctor) problem(msg, hint string, fix func() error) { d.report(false, msg, hint, fix) }
+func (d *doctor) warn(msg, hint string, fix func() error)    { d.report(true, msg, hint, fix) }
+
+func runDoctor(fix, remote bool) error {
+	d := &doctor{fix: fix}
+
+	cfg := d.checkConfig()
+	if cfg != nil {
+		if d.checkGit(cfg, remote) {
+			d.checkData(cfg)
+		}
+	}
+
+	fmt.Println()
+	switch {
+	case d.problems == 0 && d.warnings == 0 && d.fixed == 0:
+		fmt.Println("No problems found.")
+	case d.problems == 0 && d.warnings == 0:
+		fmt.Printf("Fixed %d issues.\n", d.fixed)
+	default:
+		fmt.Printf("%d problems, %d warnings", d.problems, d.warnings)
+		if d.fixed > 0 {
+			fmt.Printf(" left after fixing %d", d.fixed)
+		}
+		fmt.Println(".")
+		if d.fixable > 0 {
+			fmt.Printf("Run 'att doctor --fix' to fix %d of them.\n", d.fixable)
+		}
+	}
+	if d.problems > 0 {
+		return &cliError{code: exitProblems, err: errReported}
+	}
+	return nil
+}
+
+func (d *doctor) checkConfig() *model.Config {
+	d.section("Config")
+	path := getConfigPath()
+	cfg, err := loadConfig()
+	if err != nil {
+		d.problem(err.Error(), "Fix the file by hand, or move it aside and run 'att setup'.", nil)
+		return nil
+	}
+	if cfg == nil {
+		d.problem(fmt.Sprintf("no config file at %s", path), "Run 'att setup' to create one.", nil)
+		return nil
+	}
+	d.ok("%s (schema version %d)", path, configSchemaVersion)
+
+	changed := false
+	change := func(apply func()) func() error {
+		return func() error { apply(); changed = true; return nil }
+	}
+
+	if cfg.DataPath == "" {
+		def := getDefaultDataPath()
+		d.problem("no data path is set", "Set one with: att config set-path <path>",
+			change(func() { cfg.DataPath = def }))
+	} else if !filepath.IsAbs(cfg.DataPath) {
+		abs := expandHome(cfg.DataPath)
+		if !filepath.IsAbs(abs) {
+			abs, _ = filepath.Abs(abs)
+		}
+		d.problem(fmt.Sprintf("data path %q is relative, so it depends on the current directory", cfg.DataPath),
+			fmt.Sprintf("Set it with: att config set-path %s", abs),
+			change(func() { cfg.DataPath = abs }))
+	}
+
+	if len(cfg.Topics) == 0 {
+		d.warn("no topics configured", "Add one with: att topic add <id> <name> <goal> [emoji]", nil)
+	}
+	topicsPath := filepath.Join(cfg.DataPath, topicsFile)
+	for _, id := range sortedTopicIDs(cfg.Topics) {
+		tc := cfg.Topics[id]
+		if tc.DailyGoal < 1 {
+			d.problem(fmt.Sprintf("topic '%s' has a daily goal of %d, so it always counts as met", id, tc.DailyGoal),
+				fmt.Sprintf("Set \"daily_goal\" for '%s' in %s to 1 or more.", id, topicsPath),
+				change(func() { tc.DailyGoal = 1 }))
+		}
+		if strings.TrimSpace(tc.Name) == "" {
+			d.warn(fmt.Sprintf("topic '%s' has no name", id),
+				fmt.Sprintf("Set \"name\" for '%s' in %s.", id, topicsPath),
+				change(func() { tc.Name = id }))
+		}
+		var valid, invalid []string
+		for _, r := range tc.Reminders {
+			if _, err := parseClock(r); err != nil {
+				invalid = append(invalid, strconv.Quote(r))
+			} else {
+				valid = append(valid, r)
+			}
+		}
+		if len(invalid) > 0 {
+			d.problem(fmt.Sprintf("topic '%s' has invalid reminder times %s", id, strings.Join(invalid, ", ")),
+				fmt.Sprintf("Reset them with: att remind set %s HH:MM...", id),
+				change(func() { tc.Reminders = valid }))
+		}
+	}
+
+	if cfg.QuietHours != "" {
+		start, end, ok := strings.Cut(cfg.QuietHours, "-")
+		_, errStart := parseClock(start)
+		_, errEnd := parseClock(end)
+		if !ok || errStart != nil || errEnd != nil {
+			d.problem(fmt.Sprintf("invalid quiet hours %q", cfg.QuietHours), "Set them with: att remind quiet HH:MM-HH:MM",
+				change(func() { cfg.QuietHours = "" }))
+		}
+	}
+	for name := range cfg.Hooks {
+		if !slices.Contains(hookNames, name) {
+			d.warn(fmt.Sprintf("unknown hook %q never runs", name), fmt.Sprintf("Remove it with: att hook unset %s", name),
+				change(func() { delete(cfg.Hooks, name) }))
+		}
+	}
+	for _, wh := range cfg.Webhooks {
+		if u, err := url.Parse(wh.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			d.problem(fmt.Sprintf("webhook %q is not an http(s) URL", wh.URL), fmt.Sprintf("Remove it with: att webhook remove %s", wh.URL), nil)
+		}
+		for i, e := range wh.Events {
+			switch {
+			case canonicalEvent(e) != e:
+				d.warn(fmt.Sprintf("webhook %s subscribes to %q, which is now called %q", wh.URL, e, canonicalEvent(e)),
+					"It still works; re-add the webhook with the new name to update it.",
+					change(func() { wh.Events[i] = canonicalEvent(e) }))
+			case !slices.Contains(eventNames, e):
+				d.warn(fmt.Sprintf("webhook %s subscribes to unknown event %q", wh.URL, e), "Re-add the webhook with valid --events.", nil)
+			}
+		}
+	}
+
+	if changed {
+		if err := saveTopics(cfg); err != nil {
+			d.problem(fmt.Sprintf("saving fixes: %v", err), "", nil)
+		}
+		if err := saveConfig(cfg); err != nil {
+			d.problem(fmt.Sprintf("saving fixes: %v", err), "", nil)
+		}
+	}
+	return cfg
+}
+
+// checkGit checks the data directory and its repository, and reports
+// whether the data is worth checking.
+func (d *doctor) checkGit(cfg *model.Config, remote bool) bool {
+	d.section("Data repository")
+	dataPath := cfg.DataPath
+	if dataPath == "" {
+		return false
+	}
+	info, err := os.Stat(dataPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		d.problem(fmt.Sprintf("data directory %s does not exist", dataPath),
+			"It is created on the first check-in, or fix the path with: att config set-path <path>",
+			func() error { return initRepo(cfg) })
+		if _, err := os.Stat(dataPath); err != nil {
+			return false
+		}
+	case err != nil:
+		d.problem(fmt.Sprintf("cannot read data directory: %v", err), "", nil)
+		return false
+	case !info.IsDir():
+		d.problem(fmt.Sprintf("data path %s is a file, not a directory", dataPath), "Fix it with: att config set-path <directory>", nil)
+		return false
+	default:
+		d.ok("%s", dataPath)
+	}
+
+	if _, err := exec.LookPath("git"); err != nil {
+		d.problem("git is not installed, so nothing is committed or synced", "Install Git.", nil)
+		return true
+	}
+	if _, err := os.Stat(filepath.Join(dataPath, ".git")); err != nil {
+		d.problem("the data directory is not a Git repository, so check-ins are not committed",
+			fmt.Sprintf("Run: git -C %s init && git -C %s add progress.json && git -C %s commit -m 'Initial commit'", dataPath, dataPath, dataPath),
+			func() error { return gitInitExisting(cfg) })
+		if _, err := os.Stat(filepath.Join(dataPath, ".git")); err != nil {
+			return true
+		}
+	} else {
+		d.ok("Git repository")
+	}
+
+	for _, name := range []string{"progress.json", topicsFile} {
+		if status, err := gitOutput(dataPath, "status", "--porcelain", "--", name); err == nil && status != "" {
+			d.warn(fmt.Sprintf("%s has uncommitted changes", name), fmt.Sprintf("Commit them with: git -C %s add %s && git -C %s commit -m 'Update'", dataPath, name, dataPath),
+				func() error {
+					if err := runGit(dataPath, "add", name); err != nil {
+						return err
+					}
+					return runGit(dataPath, "commit", "-m", "Doctor: commit pending changes", "--", name)
+				})
+		}
+	}
+	if branch, err := gitOutput(dataPath, "symbolic-ref", "--short", "HEAD"); err == nil && branch != "main" && cfg.SSHURL != "" {
+		d.warn(fmt.Sprintf("the repository is on branch %q, but att syncs branch main", branch),
+			fmt.Sprintf("Rename it with: git -C %s branch -m main", dataPath),
+			func() error { return runGit(dataPath, "branch", "-m", "main") })
+	}
+
+	origin, _ := gitOutput(dataPath, "remote", "get-url", "origin")
+	switch {
+	case cfg.SSHURL == "" && origin != "":
+		d.warn(fmt.Sprintf("the repository has remote %s, but no remote is configured, so nothing syncs", origin),
+			fmt.Sprintf("Enable syncing with: att config set-remote %s", origin), nil)
+	case cfg.SSHURL != "" && origin == "":
+		d.problem(fmt.Sprintf("remote %s is configured but missing from the repository", cfg.SSHURL),
+			fmt.Sprintf("Run: att config set-remote %s", cfg.SSHURL),
+			func() error { return runGit(dataPath, "remote", "add", "origin", cfg.SSHURL) })
+	case cfg.SSHURL != "" && origin != cfg.SSHURL:
+		d.problem(fmt.Sprintf("the repository's remote is %s, but the config says %s", origin, cfg.SSHURL),
+			fmt.Sprintf("Run: att config set-remote %s", cfg.SSHURL),
+			func() error { return runGit(dataPath, "remote", "set-url", "origin", cfg.SSHURL) })
+	case cfg.SSHURL != "":
+		d.ok("remote %s", cfg.SSHURL)
+	}
+
+	if remote && cfg.SSHURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, "git", "-C", dataPath, "ls-remote", "--heads", cfg.SSHURL)
+		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			d.problem(fmt.Sprintf("cannot reach %s: %s", cfg.SSHURL, firstLine(string(out), err)),
+				"Check the URL, your network and your SSH keys.", nil)
+		} else {
+			d.ok("remote is reachable")
+		}
+	}
+
+	if entries, err := os.ReadDir(filepath.Join(outboxDir(dataPath), "failed")); err == nil && len(entries) > 0 {
+		d.warn(fmt.Sprintf("%d webhook deliveries failed for good", len(entries)),
+			fmt.Sprintf("Inspect them in %s", filepath.Join(outboxDir(dataPath), "failed")), nil)
+	}
+	return true
+}
+
+func firstLine(out string, err error) string {
+	if line, _, _ := strings.Cut(strings.TrimSpace(out), "\n"); line != "" {
+		return line
+	}
+	return err.Error()
+}
+
+// gitInitExisting turns an existing data directory into a repository,
+// keeping whatever progress.json and topics.json it has; initRepo would
+// replace the former.
+func gitInitExisting(cfg *model.Config) error {
+	if _, err := os.Stat(filepath.Join(cfg.DataPath, "progress.json")); errors.Is(err, os.ErrNotExist) {
+		return initRepo(cfg)
+	}
+	if err := runGit(cfg.DataPath, "init"); err != nil {
+		return err
+	}
+	runGit(cfg.DataPath, "add", "progress.json")
+	runGit(cfg.DataPath, "add", topicsFile)
+	if err := runGit(cfg.DataPath, "commit", "-m", "Initial commit"); err != nil {
+		return err
+	}
+	if cfg.SSHURL != "" {
+		return runGit(cfg.DataPath, "remote", "add", "origin", cfg.SSHURL)
+	}
+	return nil
+}
+
+func (d *doctor) checkData(cfg *model.Config) {
+	d.section("Data")
+	path := filepath.Join(cfg.DataPath, "progress.json")
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		d.problem(err.Error(), fmt.Sprintf("Restore a good version with: git -C %s checkout HEAD -- progress.json", cfg.DataPath), nil)
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		d.warn("progress.json does not exist yet", "It is created on the first check-in.", nil)
+		return
+	}
+	d.ok("%s (schema version %d)", path, dataSchemaVersion)
+
+	// Data fixes are applied together, in one commit, under the data lock.
+	var repairs []func(*ProgressData)
+	repair := func(fn func(*ProgressData)) func() error {
+		return func() error { repairs = append(repairs, fn); return nil }
+	}
+
+	for _, id := range sortedTopicIDs(cfg.Topics) {
+		if data.Topics[id] == nil {
+			name := cfg.Topics[id].Name
+			d.warn(fmt.Sprintf("topic '%s' is missing from progress.json", id), "It is added on its first check-in.",
+				repair(func(pd *ProgressData) {
+					if pd.Topics[id] == nil {
+						pd.Topics[id] = &TopicData{Name: name, History: []CheckIn{}}
+					}
+				}))
+		}
+	}
+	var orphans []string
+	for id := range data.Topics {
+		if cfg.Topics[id] == nil {
+			orphans = append(orphans, id)
+		}
+	}
+	slices.Sort(orphans)
+	for _, id := range orphans {
+		d.warn(fmt.Sprintf("progress.json has history for '%s', which is not a configured topic", id),
+			fmt.Sprintf("Show it again with: att topic add %s <name> <goal>", id), nil)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(data.Topics)) {
+		td := data.Topics[id]
+		bad := 0
+		latest := ""
+		var latestTime time.Time
+		for _, ci := range td.History {
+			t, err := time.Parse(time.RFC3339, ci.Date)
+			if err != nil {
+				bad++
+				continue
+			}
+			if latest == "" || t.After(latestTime) {
+				latest, latestTime = ci.Date, t
+			}
+		}
+		if bad > 0 {
+			d.problem(fmt.Sprintf("topic '%s' has %d check-ins with unreadable dates", id, bad),
+				fmt.Sprintf("Fix the \"date\" fields in %s (RFC 3339, e.g. 2025-01-31T09:15:00Z).", path), nil)
+		}
+		if td.TotalCheckIns != len(td.History) {
+			n := len(td.History)
+			d.warn(fmt.Sprintf("topic '%s' counts %d check-ins but has %d in its history", id, td.TotalCheckIns, n),
+				"Run 'att doctor --fix' to recount.",
+				repair(func(pd *ProgressData) {
+					if t := pd.Topics[id]; t != nil {
+						t.TotalCheckIns = len(t.History)
+					}
+				}))
+		}
+		if bad == 0 && td.LastDate != latest {
+			d.warn(fmt.Sprintf("topic '%s' records its last check-in as %q, but the latest in its history is %q", id, td.LastDate, latest),
+				"Run 'att doctor --fix' to recompute.",
+				repair(func(pd *ProgressData) {
+					if t := pd.Topics[id]; t != nil {
+						refreshTopicSummary(t, time.Now())
+					}
+				}))
+		}
+	}
+
+	if len(repairs) > 0 {
+		if err := applyDataRepairs(cfg, repairs); err != nil {
+			d.problem(fmt.Sprintf("saving fixes: %v", err), "", nil)
+		}
+	}
+}
+
+func applyDataRepairs(cfg *model.Config, repairs []func(*ProgressData)) error {
+	unlock, err := lockData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := loadData(cfg)
+	if err != nil {
+		return err
+	}
+	for _, fn := range repairs {
+		fn(data)
+	}
+	if err := commitData(cfg, data, "Doctor: repair progress.json"); err != nil {
+		return err
+	}
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
+	}
+	return nil
+}
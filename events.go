@@ -0,0 +1,87 @@
+package main
+
+import (
+	"time"
+
+	"att/model"
+)
+
+// Event names sent to webhooks.
+const (
+	eventCheckin         = "checkin"
+	eventGoalMet         = "goal_met"
+	eventStreakMilestone = "streak_milestone"
+	eventStreakRestarted = "streak_restarted"
+)
+
+var eventNames = []string{eventCheckin, eventGoalMet, eventStreakMilestone, eventStreakRestarted}
+
+// renamedEvents maps old event names that webhooks may still subscribe to
+// onto the current ones. streak_broken was never sent when a streak broke,
+// only at the check-in that restarted it.
+var renamedEvents = map[string]string{"streak_broken": eventStreakRestarted}
+
+// canonicalEvent returns the current name of event.
+func canonicalEvent(event string) string {
+	if renamed, ok := renamedEvents[event]; ok {
+		return renamed
+	}
+	return event
+}
+
+// streakMilestones are the streak lengths worth celebrating; after a year,
+// every further year is one too.
+var streakMilestones = []int{3, 7, 14, 30, 50, 100, 200, 365}
+
+func isStreakMilestone(streak int) bool {
+	if streak > 365 {
+		return streak%365 == 0
+	}
+	for _, m := range streakMilestones {
+		if streak == m {
+			return true
+		}
+	}
+	return false
+}
+
+// attEvent is the JSON payload describing something that happened to a
+// topic. It is documented in docs/api.md.
+type attEvent struct {
+	Event          string      `json:"event"`
+	Time           string      `json:"time"`
+	Topic          topicStatus `json:"topic"`
+	Remark         string      `json:"remark,omitempty"`
+	Streak         int         `json:"streak,omitempty"`
+	PreviousStreak int         `json:"previous_streak,omitempty"`
+}
+
+// checkinEvents derives the events caused by a check-in that brought
+// today's count to progress. A streak restarts when the first check-in of
+// the day sets it to 1 although the topic had a streak going before; that
+// is when a lapse is noticed, which may be days after the streak broke.
+func checkinEvents(topicID string, cfg *model.TopicConfig, topicData *TopicData, progress int, remark string, prevStreak int, now time.Time) []attEvent {
+	report := newCheckinReport(topicID, cfg, topicData, progress, remark)
+	base := attEvent{Time: now.Format(time.RFC3339), Topic: report.Topic, Remark: remark}
+
+	var events []attEvent
+	add := func(name string, e attEvent) {
+		e.Event = name
+		events = append(events, e)
+	}
+	if progress == 1 && topicData.Streak == 1 && prevStreak > 0 {
+		e := base
+		e.PreviousStreak = prevStreak
+		add(eventStreakRestarted, e)
+	}
+	add(eventCheckin, base)
+	if progress == cfg.DailyGoal {
+		add(eventGoalMet, base)
+	}
+	if progress == 1 && isStreakMilestone(topicData.Streak) {
+		e := base
+		e.Streak = topicData.Streak
+		add(eventStreakMilestone, e)
+	}
+	return events
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+func newExportCommand() *command {
+	exportCmd := newCommand("export", "", "Export check-in history")
+	exportCmd.add(newExportCSVCommand(), newExportICSCommand())
+	return exportCmd
+}
+
+// exportOptions are the flags shared by every export format.
+type exportOptions struct {
+	out   *string
+	since *string
+	until *string
+}
+
+func addExportFlags(cmd *command) exportOptions {
+	cmd.maxArgs = 1
+	cmd.topicArg = anyTopicArg
+	return exportOptions{
+		out:   cmd.flags.String("out", "", "write to `file` instead of stdout"),
+		since: cmd.flags.String("since", "", "only export check-ins on or after `day`"),
+		until: cmd.flags.String("until", "", "only export check-ins on or before `day`"),
+	}
+}
+
+// load resolves the optional topic argument and date flags and returns the
+// matching check-ins, oldest first.
+func (o exportOptions) load(args []string) ([]checkInEntry, error) {
+	cfg, err := requireConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var f checkInFilter
+	if len(args) > 0 {
+		if f.topicID, err = resolveTopic(cfg.Topics, args[0]); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	if *o.since != "" {
+		if f.since, err = parseDay(*o.since, now); err != nil {
+			return nil, err
+		}
+	}
+	if *o.until != "" {
+		day, err := parseDay(*o.until, now)
+		if err != nil {
+			return nil, err
+		}
+		f.until = day.AddDate(0, 0, 1)
+	}
+
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		return nil, err
+	}
+	entries := collectCheckIns(cfg, data, f)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].time.Before(entries[j].time) })
+	return entries, nil
+}
+
+// write runs fn against the --out file, or stdout when none was given.
+func (o exportOptions) write(fn func(w io.Writer) error) error {
+	if *o.out == "" {
+		return fn(os.Stdout)
+	}
+	f, err := os.Create(expandHome(*o.out))
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Exported to %s\n", *o.out)
+	return nil
+}
+
+var csvHeader = []string{"topic_id", "topic_name", "timestamp", "remark", "amount"}
+
+func newExportCSVCommand() *command {
+	csvCmd := newCommand("csv", "[topic]", "Export check-ins as CSV")
+	opts := addExportFlags(csvCmd)
+	csvCmd.examples = []string{
+		"att export csv --out history.csv",
+		"att export csv dsa --since 2025-01-01",
+	}
+	csvCmd.run = func(args []string) error {
+		entries, err := opts.load(args)
+		if err != nil {
+			return err
+		}
+		return opts.write(func(w io.Writer) error {
+			cw := csv.NewWriter(w)
+			cw.Write(csvHeader)
+			for _, e := range entries {
+				// Every check-in counts once; amount exists so imports
+				// from trackers with quantities round-trip.
+				cw.Write([]string{e.TopicID, e.TopicName, e.Date, e.Remark, "1"})
+			}
+			cw.Flush()
+			return cw.Error()
+		})
+	}
+	return csvCmd
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"att/model"
+)
+
+// User hooks run at points in att's lifecycle. Each is either an executable
+// file named after the hook in <DataPath>/hooks/, which is synced with the
+// data repo, or a shell command in config.json's "hooks" map; when both
+// exist the file runs first. A pre- hook that exits non-zero aborts the
+// operation; a failing post- hook only prints a warning.
+const (
+	hookPreCheckin   = "pre-checkin"
+	hookPostCheckin  = "post-checkin"
+	hookPreTopicAdd  = "pre-topic-add"
+	hookPostTopicAdd = "post-topic-add"
+	hookPreSave      = "pre-save"
+	hookPostSave     = "post-save"
+	hookPostSync     = "post-sync"
+)
+
+var hookNames = []string{hookPreCheckin, hookPostCheckin, hookPreTopicAdd, hookPostTopicAdd, hookPreSave, hookPostSave, hookPostSync}
+
+// hookPayload is written to a hook's stdin as JSON and mirrored in ATT_*
+// environment variables. It is documented in docs/hooks.md.
+type hookPayload struct {
+	Hook     string       `json:"hook"`
+	Time     string       `json:"time"`
+	DataPath string       `json:"data_path"`
+	Topic    *topicStatus `json:"topic,omitempty"`
+	Remark   string       `json:"remark,omitempty"`
+	Message  string       `json:"message,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+func (p hookPayload) env() []string {
+	env := []string{
+		"ATT_HOOK=" + p.Hook,
+		"ATT_DATA_PATH=" + p.DataPath,
+	}
+	// att commands run by the hook use the same config.
+	if configFileOverridden() {
+		env = append(env, "ATT_CONFIG="+attArgs()[1])
+	} else {
+		env = append(env, "ATT_PROFILE="+currentProfile())
+	}
+	if t := p.Topic; t != nil {
+		env = append(env,
+			"ATT_TOPIC="+t.ID,
+			"ATT_TOPIC_NAME="+t.Name,
+			"ATT_TODAY="+strconv.Itoa(t.Today),
+			"ATT_DAILY_GOAL="+strconv.Itoa(t.DailyGoal),
+			"ATT_STREAK="+strconv.Itoa(t.Streak),
+		)
+	}
+	if p.Remark != "" {
+		env = append(env, "ATT_REMARK="+p.Remark)
+	}
+	if p.Message != "" {
+		env = append(env, "ATT_MESSAGE="+p.Message)
+	}
+	if p.Error != "" {
+		env = append(env, "ATT_ERROR="+p.Error)
+	}
+	return env
+}
+
+// runHooks runs the hooks registered for name. Hook output goes to stderr
+// so it never mixes with att's own --json output. Hooks are not run from
+// inside another hook, so a hook that calls att cannot loop.
+func runHooks(cfg *model.Config, name string, p hookPayload) error {
+	if os.Getenv("ATT_HOOK") != "" {
+		return nil
+	}
+	dataPath := cfg.DataPath
+	var commands [][]string
+	file := filepath.Join(dataPath, "hooks", name)
+	if info, err := os.Stat(file); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+		commands = append(commands, []string{file})
+	}
+	if cfg.Hooks[name] != "" {
+		commands = append(commands, []string{"sh", "-c", cfg.Hooks[name]})
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+
+	p.Hook, p.DataPath, p.Time = name, dataPath, time.Now().Format(time.RFC3339)
+	stdin, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	for _, argv := range commands {
+		cmd := exec.Command(argv[0], argv[1:]...)
+		cmd.Dir = dataPath
+		cmd.Env = append(os.Environ(), p.env()...)
+		cmd.Stdin = bytes.NewReader(stdin)
+		cmd.Stdout = os.Stderr
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			if strings.HasPrefix(name, "pre-") {
+				return fmt.Errorf("%s hook aborted the operation: %v", name, err)
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %s hook failed: %v\n", name, err)
+		}
+	}
+	return nil
+}
+
+func newHookCommand() *command {
+	hookCmd := newCommand("hook", "", "Manage hook scripts")
+
+	listCmd := newCommand("list", "", "Show which hooks are set")
+	listCmd.aliases = []string{"ls"}
+	listCmd.maxArgs = 0
+	listCmd.run = func([]string) error { return hookList() }
+
+	setCmd := newCommand("set", "<hook> <command>", "Run a shell command for a hook")
+	setCmd.minArgs, setCmd.maxArgs = 2, 2
+	setCmd.examples = []string{
+		"att hook set post-checkin 'paplay /usr/share/sounds/freedesktop/stereo/complete.oga'",
+		"att hook set pre-checkin 'test ${#ATT_REMARK} -ge 10'",
+	}
+	setCmd.run = func(args []string) error { return hookSet(args[0], args[1]) }
+
+	unsetCmd := newCommand("unset", "<hook>", "Remove a hook's shell command")
+	unsetCmd.minArgs, unsetCmd.maxArgs = 1, 1
+	unsetCmd.run = func(args []string) error { return hookSet(args[0], "") }
+
+	installCmd, uninstallCmd := newHookInstallCommands()
+	hookCmd.add(listCmd, setCmd, unsetCmd, installCmd, uninstallCmd)
+	return hookCmd
+}
+
+func hookList() error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	dir := filepath.Join(cfg.DataPath, "hooks")
+	fmt.Printf("Hook scripts: %s/<hook>\n\n", dir)
+	for _, name := range hookNames {
+		var where []string
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			if info.Mode()&0111 != 0 {
+				where = append(where, "script")
+			} else {
+				where = append(where, "script (not executable, skipped)")
+			}
+		}
+		if c := cfg.Hooks[name]; c != "" {
+			where = append(where, strconv.Quote(c))
+		}
+		if len(where) == 0 {
+			where = []string{"-"}
+		}
+		fmt.Printf("  %-15s %s\n", name, where[0])
+		for _, w := range where[1:] {
+			fmt.Printf("  %-15s %s\n", "", w)
+		}
+	}
+	return nil
+}
+
+func hookSet(name, command string) error {
+	if !slices.Contains(hookNames, name) {
+		return usageErrorf("unknown hook %q (want one of %s)", name, strings.Join(hookNames, ", "))
+	}
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	if command == "" {
+		delete(cfg.Hooks, name)
+	} else {
+		if cfg.Hooks == nil {
+			cfg.Hooks = make(map[string]string)
+		}
+		cfg.Hooks[name] = command
+	}
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	if command == "" {
+		fmt.Printf("✓ %s hook removed\n", name)
+	} else {
+		fmt.Printf("✓ %s hook set\n", name)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"att/model"
+)
+
+// calendarFile is where --publish writes the calendar inside the data repo,
+// so it can be subscribed to from the git host's raw file URL.
+const calendarFile = "calendar.ics"
+
+func newExportICSCommand() *command {
+	icsCmd := newCommand("ics", "[topic]", "Export check-ins as an iCalendar file")
+	opts := addExportFlags(icsCmd)
+	reminders := icsCmd.flags.String("reminders", "", "add a daily reminder at `HH:MM` for each enabled topic")
+	duration := icsCmd.flags.Duration("duration", 15*time.Minute, "length of each check-in event")
+	publish := icsCmd.flags.Bool("publish", false, "write "+calendarFile+" to the data repo, commit and sync it")
+	icsCmd.examples = []string{
+		"att export ics --out att.ics",
+		"att export ics --reminders 20:00 --publish",
+	}
+	icsCmd.run = func(args []string) error {
+		if *publish && *opts.out != "" {
+			return usageErrorf("--publish and --out cannot be combined")
+		}
+		var remindAt time.Time
+		if *reminders != "" {
+			t, err := time.Parse("15:04", *reminders)
+			if err != nil {
+				return usageErrorf("invalid --reminders time %q, want HH:MM", *reminders)
+			}
+			remindAt = t
+		}
+		if *duration <= 0 {
+			return usageErrorf("--duration must be positive")
+		}
+
+		entries, err := opts.load(args)
+		if err != nil {
+			return err
+		}
+		cfg, err := requireConfig()
+		if err != nil {
+			return err
+		}
+		var reminderTopics []string
+		if *reminders != "" {
+			only := ""
+			if len(args) > 0 {
+				only, _ = resolveTopic(cfg.Topics, args[0]) // already validated by load
+			}
+			for _, id := range sortedTopicIDs(cfg.Topics) {
+				if cfg.Topics[id].Enabled && (only == "" || id == only) {
+					reminderTopics = append(reminderTopics, id)
+				}
+			}
+		}
+
+		write := func(w io.Writer) error {
+			return writeICS(w, cfg, entries, reminderTopics, remindAt, *duration, time.Now())
+		}
+		if !*publish {
+			return opts.write(write)
+		}
+
+		unlock, err := lockData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		f, err := os.Create(filepath.Join(cfg.DataPath, calendarFile))
+		if err != nil {
+			return dataErrorf("writing calendar: %v", err)
+		}
+		if err := write(f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return dataErrorf("writing calendar: %v", err)
+		}
+		runGit(cfg.DataPath, "add", calendarFile)
+		runGit(cfg.DataPath, "commit", "-m", fmt.Sprintf("Calendar: %d check-ins", len(entries)))
+		if cfg.SSHURL != "" {
+			syncRepo(cfg)
+		}
+		fmt.Fprintf(os.Stderr, "✓ Published %s\n", filepath.Join(cfg.DataPath, calendarFile))
+		return nil
+	}
+	return icsCmd
+}
+
+// writeICS writes an RFC 5545 calendar with one event per check-in and,
+// a daily recurring reminder at remindAt for each topic in reminders.
+func writeICS(w io.Writer, cfg *model.Config, entries []checkInEntry, reminders []string, remindAt time.Time, duration time.Duration, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICSLine(bw, name+":"+value)
+	}
+	const utc = "20060102T150405Z"
+	stamp := now.UTC().Format(utc)
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//att//att "+version+"//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("X-WR-CALNAME", "att check-ins")
+
+	// Identical check-ins share an ID, but calendar UIDs must be unique.
+	seen := make(map[string]int)
+	for _, e := range entries {
+		uid := e.ID
+		if n := seen[e.ID]; n > 0 {
+			uid = fmt.Sprintf("%s-%d", e.ID, n)
+		}
+		seen[e.ID]++
+		line("BEGIN", "VEVENT")
+		line("UID", uid+"@att")
+		line("DTSTAMP", stamp)
+		line("DTSTART", e.time.UTC().Format(utc))
+		line("DTEND", e.time.Add(duration).UTC().Format(utc))
+		line("SUMMARY", icsEscape(strings.TrimSpace(e.emoji+" "+e.TopicName)))
+		if e.Remark != "" {
+			line("DESCRIPTION", icsEscape(e.Remark))
+		}
+		line("CATEGORIES", icsEscape(e.TopicID))
+		line("END", "VEVENT")
+	}
+
+	// Reminders are floating local times, so they follow the calendar
+	// owner across time zones.
+	const local = "20060102T150405"
+	start := startOfDay(now).Add(time.Duration(remindAt.Hour())*time.Hour + time.Duration(remindAt.Minute())*time.Minute)
+	for _, id := range reminders {
+		tc := cfg.Topics[id]
+		line("BEGIN", "VEVENT")
+		line("UID", "reminder-"+id+"@att")
+		line("DTSTAMP", stamp)
+		line("DTSTART", start.Format(local))
+		line("DTEND", start.Add(duration).Format(local))
+		line("RRULE", "FREQ=DAILY")
+		line("SUMMARY", icsEscape(strings.TrimSpace(fmt.Sprintf("%s %s", tc.Emoji, tc.Name))))
+		line("DESCRIPTION", icsEscape(fmt.Sprintf("Daily goal: %d. Check in with: att checkin %s", tc.DailyGoal, id)))
+		line("CATEGORIES", icsEscape(id))
+		line("BEGIN", "VALARM")
+		line("ACTION", "DISPLAY")
+		line("DESCRIPTION", icsEscape(tc.Name))
+		line("TRIGGER", "PT0M")
+		line("END", "VALARM")
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// writeICSLine writes a content line with CRLF, folding it at 75 octets
+// without splitting a UTF-8 sequence.
+func writeICSLine(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	w.WriteString(s + "\r\n")
+}
+
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icsEscape(s string) string {
+	return icsEscaper.Replace(s)
+}
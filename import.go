@@ -0,0 +1,527 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"att/model"
+)
+
+// importRecord is one row read from an external source. Amount is how
+// many check-ins the row stands for; rows with a zero amount are skipped.
+type importRecord struct {
+	topicID   string
+	topicName string
+	time      time.Time
+	remark    string
+	amount    int
+}
+
+// importPlan is what an import would change, computed before anything
+// is written so it can be previewed with --dry-run.
+type importPlan struct {
+	source     string
+	records    []importRecord
+	perTopic   map[string]int
+	newTopics  map[string]*model.TopicConfig
+	mappedFrom map[string]string // topic ID -> name in the source, when different
+	unknown    []string
+	unnamed    []string // names with no letters or digits to make an ID from
+	duplicates int
+	skipped    int
+}
+
+// importOptions are the flags shared by every import format.
+type importOptions struct {
+	dryRun       *bool
+	createTopics *bool
+}
+
+// addImportFlags adds the shared import flags. Importers for habit apps
+// create missing topics by default since mapping them by hand is the point.
+func addImportFlags(cmd *command, createTopics bool) importOptions {
+	return importOptions{
+		dryRun:       cmd.flags.Bool("dry-run", false, "show what would be imported without writing anything"),
+		createTopics: cmd.flags.Bool("create-topics", createTopics, "create topics that do not exist yet"),
+	}
+}
+
+func newImportCommand() *command {
+	importCmd := newCommand("import", "", "Import check-in history from other tools")
+	importCmd.add(newImportCSVCommand(), newImportLoopCommand(), newImportHabiticaCommand(), newImportHabitsCommand())
+	return importCmd
+}
+
+func newImportCSVCommand() *command {
+	csvCmd := newCommand("csv", "<file>", "Import check-ins from a CSV file")
+	csvCmd.minArgs, csvCmd.maxArgs = 1, 1
+	opts := addImportFlags(csvCmd, false)
+	mapping := csvCmd.flags.String("map", "", "column `mapping` such as topic=Habit,timestamp=When,remark=Notes; fields are topic, name, timestamp, remark and amount, and default to the 'att export csv' column names")
+	topic := csvCmd.flags.String("topic", "", "import every row into `topic` instead of reading a topic column")
+	layout := csvCmd.flags.String("date-format", "", "Go time `layout` for the timestamp column (default: auto-detect)")
+	csvCmd.examples = []string{
+		"att import csv history.csv --dry-run",
+		"att import csv runs.csv --topic exercise --map timestamp=Date,remark=Notes",
+		"att import csv other.csv --map topic=Habit,amount=Count --create-topics",
+	}
+	csvCmd.run = func(args []string) error {
+		columns, err := parseColumnMapping(*mapping)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(expandHome(args[0]))
+		if err != nil {
+			return usageErrorf("opening %s: %v", args[0], err)
+		}
+		defer f.Close()
+
+		records, err := readCSVRecords(f, columns, *topic, *layout)
+		if err != nil {
+			return err
+		}
+		return runImport(filepath.Base(args[0]), records, opts)
+	}
+	return csvCmd
+}
+
+// csvFieldAliases lists the header names recognised for each field when no
+// explicit mapping is given, matched case-insensitively.
+var csvFieldAliases = map[string][]string{
+	"topic":     {"topic_id", "topic", "id"},
+	"name":      {"topic_name", "name"},
+	"timestamp": {"timestamp", "date", "datetime", "time"},
+	"remark":    {"remark", "remarks", "note", "notes", "comment"},
+	"amount":    {"amount", "value", "count", "quantity"},
+}
+
+func parseColumnMapping(s string) (map[string]string, error) {
+	columns := make(map[string]string)
+	if s == "" {
+		return columns, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		field, column, ok := strings.Cut(pair, "=")
+		field = strings.TrimSpace(field)
+		if _, known := csvFieldAliases[field]; !ok || !known {
+			return nil, usageErrorf("invalid --map entry %q: use field=Column with field one of topic, name, timestamp, remark, amount", pair)
+		}
+		columns[field] = strings.TrimSpace(column)
+	}
+	return columns, nil
+}
+
+func readCSVRecords(r io.Reader, columns map[string]string, defaultTopic, layout string) ([]importRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, dataErrorf("reading CSV header: %v", err)
+	}
+
+	index := make(map[string]int)
+	for field, aliases := range csvFieldAliases {
+		names := aliases
+		if col, ok := columns[field]; ok {
+			names = []string{col}
+		}
+		for i, h := range header {
+			h = strings.TrimSpace(strings.TrimPrefix(h, "\ufeff"))
+			for _, name := range names {
+				if strings.EqualFold(h, name) {
+					if _, seen := index[field]; !seen {
+						index[field] = i
+					}
+				}
+			}
+		}
+		if col, ok := columns[field]; ok {
+			if _, found := index[field]; !found {
+				return nil, usageErrorf("column %q not found in CSV header", col)
+			}
+		}
+	}
+	if _, ok := index["timestamp"]; !ok {
+		return nil, usageErrorf("no timestamp column found; use --map timestamp=<column>")
+	}
+	if _, ok := index["topic"]; !ok && defaultTopic == "" {
+		return nil, usageErrorf("no topic column found; use --map topic=<column> or --topic <id>")
+	}
+
+	get := func(row []string, field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var records []importRecord
+	line := 1
+	for {
+		row, err := cr.Read()
+		line++
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, dataErrorf("reading CSV: %v", err)
+		}
+
+		rec := importRecord{
+			topicID:   defaultTopic,
+			topicName: get(row, "name"),
+			remark:    get(row, "remark"),
+			amount:    1,
+		}
+		if defaultTopic == "" {
+			rec.topicID = get(row, "topic")
+		}
+		if rec.topicID == "" {
+			return nil, dataErrorf("line %d: empty topic", line)
+		}
+		if rec.time, err = parseTimestamp(get(row, "timestamp"), layout); err != nil {
+			return nil, dataErrorf("line %d: %v", line, err)
+		}
+		if v := get(row, "amount"); v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f < 0 {
+				return nil, dataErrorf("line %d: invalid amount %q", line, v)
+			}
+			rec.amount = int(f + 0.5)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseTimestamp parses s with layout, or tries common layouts when layout
+// is empty. Timestamps without a zone are taken as local time.
+func parseTimestamp(s, layout string) (time.Time, error) {
+	layouts := timestampLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+	for _, l := range layouts {
+		if t, err := time.ParseInLocation(l, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised timestamp %q", s)
+}
+
+// runImport plans records against the current data, prints a summary and,
+// unless this is a dry run, applies them as a single commit.
+func runImport(source string, records []importRecord, opts importOptions) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+
+	plan := planImport(cfg, data, records, *opts.createTopics)
+	plan.source = source
+	printImportSummary(plan)
+
+	if len(plan.unnamed) > 0 {
+		return usageErrorf("cannot make topic IDs from the names %s: rename them in the source to include letters or digits",
+			strings.Join(plan.unnamed, ", "))
+	}
+	if len(plan.unknown) > 0 {
+		return notFoundErrorf("unknown topics: %s (add them first or pass --create-topics)", strings.Join(plan.unknown, ", "))
+	}
+	if *opts.dryRun {
+		printImportPreview(plan)
+		fmt.Println("\nDry run: nothing was written.")
+		return nil
+	}
+	if len(plan.records) == 0 {
+		fmt.Println("Nothing to import.")
+		return nil
+	}
+	if err := applyImport(cfg, plan); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Imported %d check-ins from %s\n", len(plan.records), source)
+	return nil
+}
+
+func planImport(cfg *model.Config, data *ProgressData, records []importRecord, createTopics bool) importPlan {
+	plan := importPlan{
+		perTopic:   make(map[string]int),
+		newTopics:  make(map[string]*model.TopicConfig),
+		mappedFrom: make(map[string]string),
+	}
+
+	// A check-in is a duplicate if its topic already has one at the same
+	// instant, whether recorded earlier or earlier in this import.
+	seen := make(map[string]bool)
+	key := func(topicID string, t time.Time) string {
+		return topicID + "\x00" + strconv.FormatInt(t.Unix(), 10)
+	}
+	for topicID, topicData := range data.Topics {
+		for _, ci := range topicData.History {
+			if t, err := time.Parse(time.RFC3339, ci.Date); err == nil {
+				seen[key(topicID, t)] = true
+			}
+		}
+	}
+
+	unknown, unnamed := make(map[string]bool), make(map[string]bool)
+	for _, rec := range records {
+		if rec.amount <= 0 {
+			plan.skipped++
+			continue
+		}
+		// Sources often name topics ("Morning Run") rather than using IDs,
+		// so fall back to the slug of whatever was given.
+		if _, exists := cfg.Topics[rec.topicID]; !exists {
+			given := rec.topicID
+			rec.topicID = topicSlug(given)
+			if rec.topicID == "" {
+				unnamed[given] = true
+				continue
+			}
+			if _, exists := cfg.Topics[rec.topicID]; !exists {
+				if !createTopics {
+					unknown[given] = true
+					continue
+				}
+				if plan.newTopics[rec.topicID] == nil {
+					name := rec.topicName
+					if name == "" {
+						name = given
+					}
+					plan.newTopics[rec.topicID] = &model.TopicConfig{Name: name, Emoji: "📌", Enabled: true}
+				}
+			}
+			if given != rec.topicID {
+				plan.mappedFrom[rec.topicID] = given
+			}
+		}
+		k := key(rec.topicID, rec.time)
+		if seen[k] {
+			plan.duplicates++
+			continue
+		}
+		seen[k] = true
+
+		for i := 0; i < rec.amount; i++ {
+			plan.records = append(plan.records, rec)
+		}
+		plan.perTopic[rec.topicID] += rec.amount
+	}
+
+	for id := range unknown {
+		plan.unknown = append(plan.unknown, id)
+	}
+	sort.Strings(plan.unknown)
+	for name := range unnamed {
+		plan.unnamed = append(plan.unnamed, strconv.Quote(name))
+	}
+	sort.Strings(plan.unnamed)
+
+	for id, tc := range plan.newTopics {
+		tc.DailyGoal = inferDailyGoal(plan.records, id)
+	}
+	return plan
+}
+
+// topicSlug turns a display name into a topic ID: "Morning Run" becomes
+// "morning-run".
+func topicSlug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+// inferDailyGoal guesses a goal for a new topic: the most common number of
+// check-ins on days it was done, preferring the smaller on ties.
+func inferDailyGoal(records []importRecord, topicID string) int {
+	perDay := make(map[string]int)
+	for _, rec := range records {
+		if rec.topicID == topicID {
+			perDay[dayKey(rec.time)]++
+		}
+	}
+	freq := make(map[int]int)
+	for _, n := range perDay {
+		freq[n]++
+	}
+	goal, best := 1, 0
+	for n, f := range freq {
+		if f > best || f == best && n < goal {
+			goal, best = n, f
+		}
+	}
+	return goal
+}
+
+func applyImport(cfg *model.Config, plan importPlan) error {
+	if err := initRepo(cfg); err != nil {
+		return err
+	}
+	unlock, err := lockData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	// Pull first, so topics and history from other machines are kept.
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
+	}
+	if err := refreshTopics(cfg); err != nil {
+		return err
+	}
+	data, err := loadData(cfg)
+	if err != nil {
+		return err
+	}
+
+	// New topics go in the same commit as their check-ins, so a failed
+	// import leaves nothing behind.
+	var previous map[string]*model.TopicConfig
+	if len(plan.newTopics) > 0 {
+		previous = maps.Clone(cfg.Topics)
+		for id, tc := range plan.newTopics {
+			if _, exists := cfg.Topics[id]; !exists {
+				cfg.Topics[id] = tc
+			}
+		}
+		if _, err := stageTopics(cfg.DataPath, cfg.Topics); err != nil {
+			return err
+		}
+	}
+
+	for id := range plan.perTopic {
+		if cfg.Topics[id] == nil {
+			if previous != nil {
+				stageTopics(cfg.DataPath, previous)
+			}
+			return notFoundErrorf("topic '%s' was removed on another machine", id)
+		}
+	}
+
+	for _, rec := range plan.records {
+		topicData := data.Topics[rec.topicID]
+		if topicData == nil {
+			topicData = &TopicData{Name: cfg.Topics[rec.topicID].Name, History: []CheckIn{}}
+			data.Topics[rec.topicID] = topicData
+		}
+		topicData.History = append(topicData.History, CheckIn{
+			Date:   rec.time.Format(time.RFC3339),
+			Remark: rec.remark,
+		})
+		topicData.TotalCheckIns++
+	}
+
+	now := time.Now()
+	for id := range plan.perTopic {
+		refreshTopicSummary(data.Topics[id], now)
+	}
+
+	message := fmt.Sprintf("Import: %d check-ins from %s", len(plan.records), plan.source)
+	if err := commitData(cfg, data, message); err != nil {
+		if previous != nil {
+			stageTopics(cfg.DataPath, previous)
+		}
+		return err
+	}
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
+	}
+	return nil
+}
+
+// refreshTopicSummary re-sorts a topic's history and recomputes LastDate and
+// Streak after check-ins were added out of order or removed.
+func refreshTopicSummary(topicData *TopicData, now time.Time) {
+	sort.SliceStable(topicData.History, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339, topicData.History[i].Date)
+		tj, _ := time.Parse(time.RFC3339, topicData.History[j].Date)
+		return ti.Before(tj)
+	})
+	topicData.LastDate = ""
+	if n := len(topicData.History); n > 0 {
+		topicData.LastDate = topicData.History[n-1].Date
+	}
+	topicData.Streak = currentStreak(topicData, now)
+}
+
+func printImportSummary(plan importPlan) {
+	fmt.Printf("Import from %s\n", plan.source)
+	fmt.Printf("  + %d check-ins\n", len(plan.records))
+	ids := make([]string, 0, len(plan.perTopic))
+	for id := range plan.perTopic {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		var notes []string
+		if given, ok := plan.mappedFrom[id]; ok {
+			notes = append(notes, fmt.Sprintf("from %q", given))
+		}
+		if tc := plan.newTopics[id]; tc != nil {
+			notes = append(notes, fmt.Sprintf("new topic %q, goal %d/day", tc.Name, tc.DailyGoal))
+		}
+		if len(notes) > 0 {
+			fmt.Printf("      %-16s %5d  (%s)\n", id, plan.perTopic[id], strings.Join(notes, "; "))
+		} else {
+			fmt.Printf("      %-16s %5d\n", id, plan.perTopic[id])
+		}
+	}
+	if plan.duplicates > 0 {
+		fmt.Printf("  = %d duplicates skipped\n", plan.duplicates)
+	}
+	if plan.skipped > 0 {
+		fmt.Printf("  - %d rows with zero amount skipped\n", plan.skipped)
+	}
+	for _, id := range plan.unknown {
+		fmt.Printf("  ! unknown topic: %s\n", id)
+	}
+}
+
+func printImportPreview(plan importPlan) {
+	const previewRows = 10
+	if len(plan.records) == 0 {
+		return
+	}
+	fmt.Println("\nPreview:")
+	for i, rec := range plan.records {
+		if i == previewRows {
+			fmt.Printf("  … and %d more\n", len(plan.records)-previewRows)
+			break
+		}
+		fmt.Printf("  %s  %-16s %s\n", rec.time.Format("2006-01-02 15:04"), rec.topicID, rec.remark)
+	}
+}
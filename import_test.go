@@ -0,0 +1,227 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"att/model"
+)
+
+func TestPlanImport(t *testing.T) {
+	day := func(d, h int) time.Time { return time.Date(2025, 1, d, h, 0, 0, 0, time.Local) }
+	rec := func(topic string, at time.Time, amount int) importRecord {
+		return importRecord{topicID: topic, time: at, amount: amount}
+	}
+	cfg := &model.Config{Topics: map[string]*model.TopicConfig{
+		"dsa":         {Name: "DSA", DailyGoal: 2, Enabled: true},
+		"morning-run": {Name: "Morning Run", DailyGoal: 1, Enabled: true},
+	}}
+	data := &ProgressData{Topics: map[string]*TopicData{
+		"dsa": {Name: "DSA", History: []CheckIn{{Date: day(1, 9).Format(time.RFC3339)}}},
+	}}
+
+	tests := []struct {
+		name         string
+		records      []importRecord
+		createTopics bool
+		perTopic     map[string]int
+		duplicates   int
+		skipped      int
+		unknown      []string
+		unnamed      []string
+		newGoals     map[string]int // new topic ID -> inferred daily goal
+		mappedFrom   map[string]string
+	}{
+		{
+			name:     "new check-ins",
+			records:  []importRecord{rec("dsa", day(2, 9), 1), rec("dsa", day(3, 9), 1)},
+			perTopic: map[string]int{"dsa": 2},
+		},
+		{
+			name:       "duplicate of existing history",
+			records:    []importRecord{rec("dsa", day(1, 9), 1), rec("dsa", day(2, 9), 1)},
+			perTopic:   map[string]int{"dsa": 1},
+			duplicates: 1,
+		},
+		{
+			name:       "duplicate within the import",
+			records:    []importRecord{rec("dsa", day(2, 9), 1), rec("dsa", day(2, 9), 1)},
+			perTopic:   map[string]int{"dsa": 1},
+			duplicates: 1,
+		},
+		{
+			name:     "same time in another topic is not a duplicate",
+			records:  []importRecord{rec("dsa", day(1, 9), 0), rec("morning-run", day(1, 9), 1)},
+			perTopic: map[string]int{"morning-run": 1},
+			skipped:  1,
+		},
+		{
+			name:     "amount expands into check-ins",
+			records:  []importRecord{rec("dsa", day(2, 9), 3)},
+			perTopic: map[string]int{"dsa": 3},
+		},
+		{
+			name:    "zero amount is skipped",
+			records: []importRecord{rec("dsa", day(2, 9), 0)},
+			skipped: 1,
+		},
+		{
+			name:       "name matches a topic by slug",
+			records:    []importRecord{rec("Morning Run", day(2, 7), 1)},
+			perTopic:   map[string]int{"morning-run": 1},
+			mappedFrom: map[string]string{"morning-run": "Morning Run"},
+		},
+		{
+			name:     "unknown topics are reported once",
+			records:  []importRecord{rec("Yoga", day(2, 7), 1), rec("Yoga", day(3, 7), 1), rec("Art", day(2, 7), 1)},
+			perTopic: map[string]int{},
+			unknown:  []string{"Art", "Yoga"},
+		},
+		{
+			name:         "unknown topics are created",
+			records:      []importRecord{rec("Yoga", day(2, 7), 1), rec("Yoga", day(2, 19), 1), rec("Yoga", day(3, 7), 1), rec("Yoga", day(3, 19), 1)},
+			createTopics: true,
+			perTopic:     map[string]int{"yoga": 4},
+			newGoals:     map[string]int{"yoga": 2},
+			mappedFrom:   map[string]string{"yoga": "Yoga"},
+		},
+		{
+			name:         "names without letters or digits",
+			records:      []importRecord{rec("🏃", day(2, 7), 1), rec("--", day(2, 7), 1)},
+			createTopics: true,
+			perTopic:     map[string]int{},
+			unnamed:      []string{`"--"`, `"🏃"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := planImport(cfg, data, tt.records, tt.createTopics)
+
+			if tt.perTopic == nil {
+				tt.perTopic = map[string]int{}
+			}
+			if !reflect.DeepEqual(plan.perTopic, tt.perTopic) {
+				t.Errorf("perTopic = %v, want %v", plan.perTopic, tt.perTopic)
+			}
+			total := 0
+			for _, n := range tt.perTopic {
+				total += n
+			}
+			if len(plan.records) != total {
+				t.Errorf("%d records, want %d", len(plan.records), total)
+			}
+			if plan.duplicates != tt.duplicates || plan.skipped != tt.skipped {
+				t.Errorf("duplicates, skipped = %d, %d; want %d, %d", plan.duplicates, plan.skipped, tt.duplicates, tt.skipped)
+			}
+			if !reflect.DeepEqual(plan.unknown, tt.unknown) {
+				t.Errorf("unknown = %q, want %q", plan.unknown, tt.unknown)
+			}
+			if !reflect.DeepEqual(plan.unnamed, tt.unnamed) {
+				t.Errorf("unnamed = %q, want %q", plan.unnamed, tt.unnamed)
+			}
+			goals := make(map[string]int)
+			for id, tc := range plan.newTopics {
+				goals[id] = tc.DailyGoal
+			}
+			if tt.newGoals == nil {
+				tt.newGoals = map[string]int{}
+			}
+			if !reflect.DeepEqual(goals, tt.newGoals) {
+				t.Errorf("new topic goals = %v, want %v", goals, tt.newGoals)
+			}
+			if tt.mappedFrom == nil {
+				tt.mappedFrom = map[string]string{}
+			}
+			if !reflect.DeepEqual(plan.mappedFrom, tt.mappedFrom) {
+				t.Errorf("mappedFrom = %v, want %v", plan.mappedFrom, tt.mappedFrom)
+			}
+		})
+	}
+}
+
+func TestTopicSlug(t *testing.T) {
+	tests := map[string]string{
+		"Morning Run":       "morning-run",
+		"  Read -- 20 min ": "read-20-min",
+		"DSA!":              "dsa",
+		"already-a-slug":    "already-a-slug",
+		"Café":              "café",
+		"💻 Coding":          "coding",
+		"🏃":                 "",
+		"":                  "",
+	}
+	for in, want := range tests {
+		if got := topicSlug(in); got != want {
+			t.Errorf("topicSlug(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestParseColumnMapping(t *testing.T) {
+	got, err := parseColumnMapping("topic=Habit, amount = Count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"topic": "Habit", "amount": "Count"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for _, bad := range []string{"topic", "colour=Red", "=Habit"} {
+		if _, err := parseColumnMapping(bad); exitCode(err) != exitUsage {
+			t.Errorf("parseColumnMapping(%q) error = %v, want a usage error", bad, err)
+		}
+	}
+}
+
+func TestReadCSVRecords(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		columns map[string]string
+		topic   string
+		want    []importRecord
+		errCode int
+	}{
+		{
+			name: "aliases and BOM",
+			csv:  "\ufeffTopic,Date,Notes,Count\ndsa,2025-01-02,two sums,2\n",
+			want: []importRecord{{topicID: "dsa", time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local), remark: "two sums", amount: 2}},
+		},
+		{
+			name:    "mapped columns",
+			csv:     "Habit,When\nRead,2025-01-02 21:30\n",
+			columns: map[string]string{"topic": "Habit", "timestamp": "When"},
+			want:    []importRecord{{topicID: "Read", time: time.Date(2025, 1, 2, 21, 30, 0, 0, time.Local), amount: 1}},
+		},
+		{
+			name:  "default topic",
+			csv:   "date\n2025-01-02\n",
+			topic: "dsa",
+			want:  []importRecord{{topicID: "dsa", time: time.Date(2025, 1, 2, 0, 0, 0, 0, time.Local), amount: 1}},
+		},
+		{name: "no timestamp column", csv: "topic\ndsa\n", errCode: exitUsage},
+		{name: "no topic column", csv: "date\n2025-01-02\n", errCode: exitUsage},
+		{name: "mapped column missing", csv: "topic,date\n", columns: map[string]string{"remark": "Why"}, errCode: exitUsage},
+		{name: "bad timestamp", csv: "topic,date\ndsa,yesterday-ish\n", errCode: exitData},
+		{name: "negative amount", csv: "topic,date,amount\ndsa,2025-01-02,-1\n", errCode: exitData},
+		{name: "empty topic", csv: "topic,date\n,2025-01-02\n", errCode: exitData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readCSVRecords(strings.NewReader(tt.csv), tt.columns, tt.topic, "")
+			if tt.errCode != 0 {
+				if exitCode(err) != tt.errCode {
+					t.Fatalf("error = %v, want exit code %d", err, tt.errCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Importers for exports from other habit apps. Each reads its source into
+// importRecords; planning, topic creation and committing are shared with
+// `att import csv`.
+
+func newImportLoopCommand() *command {
+	loopCmd := newCommand("loop", "<export.zip|dir|backup.db>", "Import from Loop Habit Tracker")
+	loopCmd.minArgs, loopCmd.maxArgs = 1, 1
+	opts := addImportFlags(loopCmd, true)
+	loopCmd.examples = []string{
+		"att import loop 'Loop Habits CSV 2025-01-31.zip' --dry-run",
+		"att import loop ~/Downloads/loop-export/",
+		"att import loop 'Loop Habits Backup 2025-01-31.db'   # needs sqlite3",
+	}
+	loopCmd.run = func(args []string) error {
+		src := expandHome(args[0])
+		var records []importRecord
+		var err error
+		switch {
+		case strings.HasSuffix(strings.ToLower(src), ".db"):
+			if _, err := os.Stat(src); err != nil {
+				return usageErrorf("opening %s: %v", args[0], err)
+			}
+			records, err = readLoopDatabase(src)
+		case strings.HasSuffix(strings.ToLower(src), ".zip"):
+			var zr *zip.ReadCloser
+			if zr, err = zip.OpenReader(src); err != nil {
+				return usageErrorf("opening %s: %v", args[0], err)
+			}
+			defer zr.Close()
+			records, err = readLoopCSV(zr)
+		default:
+			info, statErr := os.Stat(src)
+			if statErr != nil || !info.IsDir() {
+				return usageErrorf("%s is not a Loop .zip export, export directory or .db backup", args[0])
+			}
+			records, err = readLoopCSV(os.DirFS(src))
+		}
+		if err != nil {
+			return err
+		}
+		return runImport(filepath.Base(src), records, opts)
+	}
+	return loopCmd
+}
+
+// Loop stores boolean check marks as 2 (done), 1 (implied by the habit's
+// frequency), 0 (not done), -1 (unknown) and 3 (skipped). Numeric habits
+// store the entered amount multiplied by 1000.
+const (
+	loopYesManual       = 2
+	loopNumericHabit    = 1
+	loopNumericMultiple = 1000.0
+)
+
+func loopAmount(value float64, numeric bool) int {
+	if numeric {
+		if value <= 0 {
+			return 0
+		}
+		return max(1, int(math.Round(value/loopNumericMultiple)))
+	}
+	if value == loopYesManual {
+		return 1
+	}
+	return 0
+}
+
+// readLoopCSV reads a Loop "Export as CSV" archive: Habits.csv lists the
+// habits and each habit's "NNN Name/Checkmarks.csv" holds date,value rows.
+func readLoopCSV(fsys fs.FS) ([]importRecord, error) {
+	root, err := findLoopRoot(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	numeric := make(map[string]bool)
+	if f, err := fsys.Open(path.Join(root, "Habits.csv")); err == nil {
+		rows, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		if err != nil {
+			return nil, dataErrorf("reading Habits.csv: %v", err)
+		}
+		if len(rows) > 0 {
+			nameCol, typeCol := -1, -1
+			for i, h := range rows[0] {
+				switch strings.ToLower(strings.TrimSpace(h)) {
+				case "name":
+					nameCol = i
+				case "type":
+					typeCol = i
+				}
+			}
+			for _, row := range rows[1:] {
+				if nameCol >= 0 && typeCol >= 0 && typeCol < len(row) {
+					numeric[row[nameCol]] = strings.TrimSpace(row[typeCol]) == strconv.Itoa(loopNumericHabit)
+				}
+			}
+		}
+	}
+
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, dataErrorf("reading Loop export: %v", err)
+	}
+	habitDir := regexp.MustCompile(`^\d{3} (.+)$`)
+
+	var records []importRecord
+	for _, entry := range entries {
+		m := habitDir.FindStringSubmatch(entry.Name())
+		if !entry.IsDir() || m == nil {
+			continue
+		}
+		name := m[1]
+		f, err := fsys.Open(path.Join(root, entry.Name(), "Checkmarks.csv"))
+		if err != nil {
+			continue
+		}
+		cr := csv.NewReader(f)
+		cr.FieldsPerRecord = -1
+		rows, err := cr.ReadAll()
+		f.Close()
+		if err != nil {
+			return nil, dataErrorf("reading %s/Checkmarks.csv: %v", entry.Name(), err)
+		}
+		for _, row := range rows {
+			if len(row) < 2 {
+				continue
+			}
+			day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(row[0]), time.Local)
+			if err != nil {
+				continue // header or malformed line
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(row[1]), 64)
+			if err != nil {
+				continue
+			}
+			records = append(records, importRecord{
+				topicID:   name,
+				topicName: name,
+				time:      day,
+				remark:    "Imported from Loop Habit Tracker",
+				amount:    loopAmount(value, numeric[name]),
+			})
+		}
+	}
+	if records == nil {
+		return nil, dataErrorf("no habit check marks found in Loop export")
+	}
+	return records, nil
+}
+
+// findLoopRoot locates the directory holding Habits.csv, which is either
+// the top level or a single folder inside the archive.
+func findLoopRoot(fsys fs.FS) (string, error) {
+	if _, err := fs.Stat(fsys, "Habits.csv"); err == nil {
+		return ".", nil
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "", dataErrorf("reading Loop export: %v", err)
+	}
+	for _, e := range entries {
+		if _, err := fs.Stat(fsys, path.Join(e.Name(), "Habits.csv")); e.IsDir() && err == nil {
+			return e.Name(), nil
+		}
+	}
+	return "", dataErrorf("Habits.csv not found: is this a Loop Habit Tracker CSV export?")
+}
+
+// readLoopDatabase reads a Loop "Export full backup" SQLite file through the
+// sqlite3 command-line tool, avoiding a cgo dependency.
+func readLoopDatabase(dbPath string) ([]importRecord, error) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		return nil, usageErrorf("importing a Loop .db backup needs the sqlite3 command; use the CSV export instead")
+	}
+
+	query := func(sql string) ([][]string, error) {
+		var stderr bytes.Buffer
+		cmd := exec.Command("sqlite3", "-readonly", "-csv", dbPath, sql)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, errors.New(strings.TrimSpace(stderr.String()))
+		}
+		return csv.NewReader(bytes.NewReader(out)).ReadAll()
+	}
+
+	const base = "SELECT h.name, r.timestamp, r.value%s FROM Repetitions r JOIN Habits h ON h.id = r.habit"
+	rows, err := query(strings.Replace(base, "%s", ", h.type", 1))
+	if err != nil {
+		// Backups from versions before numeric habits have no type column.
+		if rows, err = query(strings.Replace(base, "%s", "", 1)); err != nil {
+			return nil, dataErrorf("reading Loop backup: %v", err)
+		}
+	}
+
+	var records []importRecord
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		ms, err1 := strconv.ParseInt(row[1], 10, 64)
+		value, err2 := strconv.ParseFloat(row[2], 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		numeric := len(row) > 3 && row[3] == strconv.Itoa(loopNumericHabit)
+		// Loop timestamps are midnight UTC of the day the habit was done.
+		y, m, d := time.UnixMilli(ms).UTC().Date()
+		records = append(records, importRecord{
+			topicID:   row[0],
+			topicName: row[0],
+			time:      time.Date(y, m, d, 0, 0, 0, 0, time.Local),
+			remark:    "Imported from Loop Habit Tracker",
+			amount:    loopAmount(value, numeric),
+		})
+	}
+	if records == nil {
+		return nil, dataErrorf("no repetitions found in Loop backup")
+	}
+	return records, nil
+}
+
+func newImportHabiticaCommand() *command {
+	habiticaCmd := newCommand("habitica", "<user-data.json>", "Import dailies and habits from a Habitica data export")
+	habiticaCmd.minArgs, habiticaCmd.maxArgs = 1, 1
+	opts := addImportFlags(habiticaCmd, true)
+	habiticaCmd.run = func(args []string) error {
+		raw, err := os.ReadFile(expandHome(args[0]))
+		if err != nil {
+			return usageErrorf("opening %s: %v", args[0], err)
+		}
+		records, err := readHabitica(raw)
+		if err != nil {
+			return err
+		}
+		return runImport(filepath.Base(args[0]), records, opts)
+	}
+	return habiticaCmd
+}
+
+// habiticaTask is the subset of a Habitica task needed to rebuild history.
+type habiticaTask struct {
+	Text    string `json:"text"`
+	History []struct {
+		Date      json.Number `json:"date"`
+		Completed bool        `json:"completed"`
+		ScoredUp  int         `json:"scoredUp"`
+	} `json:"history"`
+}
+
+// readHabitica turns completed dailies into one check-in each and positive
+// habit scores into one check-in per +1.
+func readHabitica(raw []byte) ([]importRecord, error) {
+	var export struct {
+		Tasks struct {
+			Habits  []habiticaTask `json:"habits"`
+			Dailies []habiticaTask `json:"dailys"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal(raw, &export); err != nil {
+		return nil, dataErrorf("parsing Habitica export: %v", err)
+	}
+
+	var records []importRecord
+	add := func(task habiticaTask, amount func(i int) int) {
+		for i, h := range task.History {
+			ms, err := h.Date.Int64()
+			if err != nil {
+				continue
+			}
+			records = append(records, importRecord{
+				topicID:   task.Text,
+				topicName: task.Text,
+				time:      time.UnixMilli(ms).Local(),
+				remark:    "Imported from Habitica",
+				amount:    amount(i),
+			})
+		}
+	}
+	for _, t := range export.Tasks.Dailies {
+		add(t, func(i int) int {
+			if t.History[i].Completed {
+				return 1
+			}
+			return 0
+		})
+	}
+	for _, t := range export.Tasks.Habits {
+		add(t, func(i int) int { return t.History[i].ScoredUp })
+	}
+	if records == nil {
+		return nil, dataErrorf("no task history found in Habitica export")
+	}
+	return records, nil
+}
+
+func newImportHabitsCommand() *command {
+	habitsCmd := newCommand("habits", "<file>", "Import a generic habit,date[,value[,note]] CSV")
+	habitsCmd.minArgs, habitsCmd.maxArgs = 1, 1
+	opts := addImportFlags(habitsCmd, true)
+	layout := habitsCmd.flags.String("date-format", "", "Go time `layout` for the date column (default: auto-detect)")
+	habitsCmd.examples = []string{
+		"att import habits streaks.csv --dry-run",
+	}
+	habitsCmd.run = func(args []string) error {
+		f, err := os.Open(expandHome(args[0]))
+		if err != nil {
+			return usageErrorf("opening %s: %v", args[0], err)
+		}
+		defer f.Close()
+		records, err := readGenericHabits(f, *layout)
+		if err != nil {
+			return err
+		}
+		return runImport(filepath.Base(args[0]), records, opts)
+	}
+	return habitsCmd
+}
+
+// readGenericHabits reads positional habit,date[,value[,note]] rows. A
+// header row is skipped when its date column does not parse, and a missing
+// value counts as one check-in.
+func readGenericHabits(r io.Reader, layout string) ([]importRecord, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, dataErrorf("reading CSV: %v", err)
+	}
+
+	var records []importRecord
+	for i, row := range rows {
+		if len(row) < 2 {
+			return nil, dataErrorf("line %d: expected habit,date[,value[,note]]", i+1)
+		}
+		habit := strings.TrimSpace(row[0])
+		t, err := parseTimestamp(strings.TrimSpace(row[1]), layout)
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, dataErrorf("line %d: %v", i+1, err)
+		}
+		rec := importRecord{topicID: habit, topicName: habit, time: t, amount: 1}
+		if len(row) > 2 && strings.TrimSpace(row[2]) != "" {
+			v, err := strconv.ParseFloat(strings.TrimSpace(row[2]), 64)
+			if err != nil || v < 0 {
+				return nil, dataErrorf("line %d: invalid value %q", i+1, row[2])
+			}
+			rec.amount = int(math.Round(v))
+		}
+		if len(row) > 3 {
+			rec.remark = strings.TrimSpace(row[3])
+		}
+		if habit == "" {
+			return nil, dataErrorf("line %d: empty habit name", i+1)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
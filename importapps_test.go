@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoopAmount(t *testing.T) {
+	tests := []struct {
+		value   float64
+		numeric bool
+		want    int
+	}{
+		{2, false, 1},  // yes, entered by hand
+		{1, false, 0},  // yes, implied by the habit's frequency
+		{0, false, 0},  // no
+		{-1, false, 0}, // unknown
+		{3000, true, 3},
+		{2400, true, 2},
+		{200, true, 1},
+		{0, true, 0},
+		{-1, true, 0},
+	}
+	for _, tt := range tests {
+		if got := loopAmount(tt.value, tt.numeric); got != tt.want {
+			t.Errorf("loopAmount(%v, %v) = %d, want %d", tt.value, tt.numeric, got, tt.want)
+		}
+	}
+}
+
+// summarize renders records as "topic date amount" lines for comparison.
+func summarize(records []importRecord) string {
+	var b strings.Builder
+	for _, r := range records {
+		b.WriteString(r.topicID + " " + r.time.Format("2006-01-02") + " ")
+		b.WriteString(strings.Repeat("+", r.amount) + "\n")
+	}
+	return b.String()
+}
+
+func TestReadLoopCSV(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Loop Habits CSV 2025-01-05/Habits.csv":                  {Data: []byte("Position,Name,Type\n001,Meditate,0\n002,Pushups,1\n")},
+		"Loop Habits CSV 2025-01-05/001 Meditate/Checkmarks.csv": {Data: []byte("2025-01-03,2\n2025-01-02,1\n2025-01-01,0\n")},
+		"Loop Habits CSV 2025-01-05/002 Pushups/Checkmarks.csv":  {Data: []byte("Date,Value\n2025-01-02,3000\n")},
+		"Loop Habits CSV 2025-01-05/Scores.csv":                  {Data: []byte("")},
+	}
+	records, err := readLoopCSV(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Meditate 2025-01-03 +\nMeditate 2025-01-02 \nMeditate 2025-01-01 \nPushups 2025-01-02 +++\n"
+	if got := summarize(records); got != want {
+		t.Errorf("got\n%swant\n%s", got, want)
+	}
+
+	if _, err := readLoopCSV(fstest.MapFS{"notes.txt": {}}); exitCode(err) != exitData {
+		t.Errorf("export without Habits.csv: error = %v, want a data error", err)
+	}
+}
+
+func TestReadHabitica(t *testing.T) {
+	day2 := time.Date(2025, 1, 2, 8, 0, 0, 0, time.Local).UnixMilli()
+	day3 := time.Date(2025, 1, 3, 8, 0, 0, 0, time.Local).UnixMilli()
+	raw := fmt.Sprintf(`{"tasks": {
+		"dailys": [{"text": "Read", "history": [{"date": %d, "completed": true}, {"date": %d, "completed": false}]}],
+		"habits": [{"text": "Water", "history": [{"date": %d, "scoredUp": 4}]}]
+	}}`, day2, day3, day2)
+	records, err := readHabitica([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Read 2025-01-02 +\nRead 2025-01-03 \nWater 2025-01-02 ++++\n"
+	if got := summarize(records); got != want {
+		t.Errorf("got\n%swant\n%s", got, want)
+	}
+
+	for _, bad := range []string{`{"tasks": `, `{"tasks": {}}`} {
+		if _, err := readHabitica([]byte(bad)); exitCode(err) != exitData {
+			t.Errorf("readHabitica(%q) error = %v, want a data error", bad, err)
+		}
+	}
+}
+
+func TestReadGenericHabits(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    string
+		remark  string // remark of the first record
+		errCode int
+	}{
+		{
+			name: "header and defaults",
+			csv:  "habit,date,value,note\nRead,2025-01-02\nWater,2025-01-02,2.6,big glasses\n",
+			want: "Read 2025-01-02 +\nWater 2025-01-02 +++\n",
+		},
+		{
+			name:   "no header",
+			csv:    "Read,2025-01-02,1,chapter 3\n",
+			want:   "Read 2025-01-02 +\n",
+			remark: "chapter 3",
+		},
+		{name: "too few fields", csv: "Read\n", errCode: exitData},
+		{name: "bad date after the first line", csv: "Read,2025-01-02\nRead,someday\n", errCode: exitData},
+		{name: "negative value", csv: "Read,2025-01-02,-1\n", errCode: exitData},
+		{name: "empty habit", csv: ",2025-01-02\n", errCode: exitData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := readGenericHabits(strings.NewReader(tt.csv), "")
+			if tt.errCode != 0 {
+				if exitCode(err) != tt.errCode {
+					t.Fatalf("error = %v, want exit code %d", err, tt.errCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := summarize(records); got != tt.want {
+				t.Errorf("got\n%swant\n%s", got, tt.want)
+			}
+			if tt.remark != "" && records[0].remark != tt.remark {
+				t.Errorf("remark = %q, want %q", records[0].remark, tt.remark)
+			}
+		})
+	}
+}
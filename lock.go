@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockData takes an exclusive lock on the data repo so a read-modify-write
+// of progress.json by one att process (the CLI or `att serve`) cannot
+// interleave with another's. It blocks until the lock is free and returns
+// a func that releases it. The lock file lives in .git so it is never
+// committed; before the repo exists there is nothing to protect.
+func lockData(dataPath string) (func(), error) {
+	gitDir := filepath.Join(dataPath, ".git")
+	if _, err := os.Stat(gitDir); err != nil {
+		return func() {}, nil
+	}
+	f, err := os.OpenFile(filepath.Join(gitDir, "att.lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, dataErrorf("opening lock: %v", err)
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, dataErrorf("locking data: %v", err)
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"att/model"
+	"att/ui"
+)
+
+// checkInEntry is a check-in together with the topic it belongs to. It is
+// also the documented `att log` output schema.
+type checkInEntry struct {
+	ID        string `json:"id"`
+	TopicID   string `json:"topic"`
+	TopicName string `json:"topic_name"`
+	Date      string `json:"date"`
+	Remark    string `json:"remark"`
+
+	emoji string
+	time  time.Time
+}
+
+var checkInEntryHeader = []string{"id", "topic", "topic_name", "date", "remark"}
+
+func (e checkInEntry) tsvRow() []string {
+	return []string{e.ID, e.TopicID, e.TopicName, e.Date, e.Remark}
+}
+
+// checkInID derives a short, stable identifier for a check-in from its
+// topic, timestamp and remark, in the spirit of abbreviated git hashes.
+func checkInID(topicID string, ci CheckIn) string {
+	sum := sha1.Sum([]byte(topicID + "\x00" + ci.Date + "\x00" + ci.Remark))
+	return hex.EncodeToString(sum[:4])
+}
+
+// checkInFilter selects check-ins. Zero values match everything; until is
+// exclusive.
+type checkInFilter struct {
+	topicID string
+	since   time.Time
+	until   time.Time
+	grep    *regexp.Regexp
+}
+
+// collectCheckIns returns the check-ins matching f, newest first.
+func collectCheckIns(cfg *model.Config, data *ProgressData, f checkInFilter) []checkInEntry {
+	var entries []checkInEntry
+	for topicID, topicData := range data.Topics {
+		if f.topicID != "" && topicID != f.topicID {
+			continue
+		}
+		name, emoji := topicData.Name, ""
+		if tc := cfg.Topics[topicID]; tc != nil {
+			name, emoji = tc.Name, tc.Emoji
+		}
+		for _, ci := range topicData.History {
+			t, err := time.Parse(time.RFC3339, ci.Date)
+			if err != nil {
+				continue
+			}
+			if !f.since.IsZero() && t.Before(f.since) {
+				continue
+			}
+			if !f.until.IsZero() && !t.Before(f.until) {
+				continue
+			}
+			if f.grep != nil && !f.grep.MatchString(ci.Remark) {
+				continue
+			}
+			entries = append(entries, checkInEntry{
+				ID:        checkInID(topicID, ci),
+				TopicID:   topicID,
+				TopicName: name,
+				Date:      ci.Date,
+				Remark:    ci.Remark,
+				emoji:     emoji,
+				time:      t,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].time.Equal(entries[j].time) {
+			return entries[i].time.After(entries[j].time)
+		}
+		return entries[i].TopicID < entries[j].TopicID
+	})
+	return entries
+}
+
+// deleteCheckin removes the check-in with the given ID, refreshes its
+// topic's summary, and commits and syncs the data repo. Identical check-ins
+// share an ID; only one of them is removed.
+func deleteCheckin(cfg *model.Config, id string) (checkInEntry, error) {
+	unlock, err := lockData(cfg.DataPath)
+	if err != nil {
+		return checkInEntry{}, err
+	}
+	defer unlock()
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		return checkInEntry{}, err
+	}
+
+	for topicID, topicData := range data.Topics {
+		for i, ci := range topicData.History {
+			if checkInID(topicID, ci) != id {
+				continue
+			}
+			topicData.History = append(topicData.History[:i], topicData.History[i+1:]...)
+			if topicData.TotalCheckIns > 0 {
+				topicData.TotalCheckIns--
+			}
+			refreshTopicSummary(topicData, time.Now())
+
+			entry := checkInEntry{ID: id, TopicID: topicID, TopicName: topicData.Name, Date: ci.Date, Remark: ci.Remark}
+			if tc := cfg.Topics[topicID]; tc != nil {
+				entry.TopicName = tc.Name
+			}
+			if err := commitData(cfg, data, fmt.Sprintf("Delete: %s check-in %s", topicID, id)); err != nil {
+				return checkInEntry{}, err
+			}
+			if cfg.SSHURL != "" {
+				syncRepo(cfg)
+			}
+			return entry, nil
+		}
+	}
+	return checkInEntry{}, notFoundErrorf("no check-in with id %s", id)
+}
+
+func newLogCommand() *command {
+	logCmd := newCommand("log", "[topic]", "List past check-ins grouped by day")
+	logCmd.maxArgs = 1
+	logCmd.topicArg = anyTopicArg
+	logFormat := addFormatFlags(logCmd)
+	since := logCmd.flags.String("since", "", "only show check-ins on or after `day` (YYYY-MM-DD, yesterday, 7d, ...)")
+	until := logCmd.flags.String("until", "", "only show check-ins on or before `day`")
+	limit := logCmd.flags.Int("limit", 0, "show at most `n` check-ins (newest first)")
+	grep := logCmd.flags.String("grep", "", "only show check-ins whose remark matches `regexp` (case-insensitive)")
+	logCmd.examples = []string{
+		"att log",
+		"att log dsa --since 7d",
+		"att log --since 2025-01-01 --until 2025-01-31 --grep 'binary search'",
+		"att log --limit 20 --json",
+	}
+	logCmd.run = func(args []string) error {
+		format, err := logFormat()
+		if err != nil {
+			return err
+		}
+		cfg, err := requireConfig()
+		if err != nil {
+			return err
+		}
+
+		var f checkInFilter
+		if len(args) > 0 {
+			if f.topicID, err = resolveTopic(cfg.Topics, args[0]); err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		if *since != "" {
+			if f.since, err = parseDay(*since, now); err != nil {
+				return err
+			}
+		}
+		if *until != "" {
+			day, err := parseDay(*until, now)
+			if err != nil {
+				return err
+			}
+			f.until = day.AddDate(0, 0, 1)
+		}
+		if *grep != "" {
+			if f.grep, err = regexp.Compile("(?i)" + *grep); err != nil {
+				return usageErrorf("invalid --grep pattern: %v", err)
+			}
+		}
+		if *limit < 0 {
+			return usageErrorf("--limit must not be negative")
+		}
+
+		data, err := readData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+		entries := collectCheckIns(cfg, data, f)
+		if *limit > 0 && len(entries) > *limit {
+			entries = entries[:*limit]
+		}
+		return showLog(entries, format)
+	}
+	return logCmd
+}
+
+func showLog(entries []checkInEntry, format string) error {
+	if format != formatText {
+		if entries == nil {
+			entries = []checkInEntry{}
+		}
+		var rows [][]string
+		for _, e := range entries {
+			rows = append(rows, e.tsvRow())
+		}
+		return writeStructured(os.Stdout, format, entries, checkInEntryHeader, rows)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No check-ins found")
+		return nil
+	}
+
+	dayStyle := lipgloss.NewStyle().Foreground(ui.PrimaryColor).Bold(true)
+	idStyle := lipgloss.NewStyle().Foreground(ui.WarningColor)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.MutedColor)
+
+	idWidth := 0
+	for _, e := range entries {
+		idWidth = max(idWidth, len(e.TopicID))
+	}
+
+	var currentDay time.Time
+	for i, e := range entries {
+		local := e.time.Local()
+		if day := startOfDay(local); !day.Equal(currentDay) {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Println(dayStyle.Render(local.Format("Monday, 02 Jan 2006")))
+			currentDay = day
+		}
+		fmt.Printf("  %s  %s  %s %-*s  %s\n",
+			idStyle.Render(e.ID),
+			mutedStyle.Render(local.Format("15:04")),
+			e.emoji, idWidth, e.TopicID,
+			e.Remark)
+	}
+	return nil
+}
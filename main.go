@@ -2,10 +2,14 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +35,8 @@ type TopicData struct {
 }
 
 type ProgressData struct {
+	// Version is the schema version of progress.json; see schema.go.
+	Version int                   `json:"version"`
 	Created string                `json:"created"`
 	Topics  map[string]*TopicData `json:"topics"`
 }
@@ -44,89 +50,219 @@ type Dashboard struct {
 	err    error
 }
 
+var version = "v1.0.1"
+
+// configOverride is set by the global --config flag.
+var configOverride string
+
 func main() {
+	err := newRootCommand().execute(os.Args[1:])
+	if err != nil && !errors.Is(err, errUsagePrinted) && !errors.Is(err, errReported) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+	os.Exit(exitCode(err))
+}
 
-	VERSION := "v1.0.1"
-	if len(os.Args) == 1 {
-		showDashboard()
-		return
-	}
-
-	command := os.Args[1]
-
-	switch command {
-	case "checkin", "c":
-		if len(os.Args) < 4 {
-			fmt.Println("Usage: att checkin <topic> <remark>")
-			os.Exit(1)
-		}
-		checkin(os.Args[2], strings.Join(os.Args[3:], " "))
-	case "topic", "t":
-		handleTopicCommand()
-	case "config":
-		handleConfigCommand()
-	case "setup":
-		runSetup()
-	case "help", "--help", "-h":
-		showHelp()
-	case "version", "--version", "-v":
-		fmt.Println(VERSION)
-	default:
-		fmt.Printf("Unknown command: %s\n", command)
-		fmt.Println("Run 'att help' for usage")
-		os.Exit(1)
+func newRootCommand() *command {
+	root := newCommand("att", "", "A Git-backed progress tracker for your daily goals.")
+	root.flags.StringVar(&configOverride, "config", "", "use `path` as the config file")
+	root.flags.StringVar(&profileOverride, "profile", "", "use profile `name` (default: $ATT_PROFILE or the one chosen with 'att profile switch')")
+	showVersion := root.flags.Bool("version", false, "print version and exit")
+	root.flags.BoolVar(showVersion, "v", false, "shorthand for --version")
+	root.usageFunc = func(w io.Writer) { fmt.Fprintln(w, helpText) }
+
+	root.run = func(args []string) error {
+		if *showVersion {
+			fmt.Println(version)
+			return nil
+		}
+		if len(args) > 0 {
+			return usageErrorf("unknown command: %s\nRun 'att help' for usage", args[0])
+		}
+		return showDashboard()
+	}
+
+	checkinCmd := newCommand("checkin", "[topic] [remark]", "Record a check-in (prompts for anything missing)")
+	checkinCmd.aliases = []string{"c"}
+	checkinCmd.topicArg = enabledTopicArg
+	checkinCmd.examples = []string{
+		`att checkin dsa "Solved two sum problem"`,
+		`att c read "Read 30 pages"     # matches 'reading' by prefix`,
+		`att checkin                    # pick a topic interactively`,
+	}
+	checkinFormat := addFormatFlags(checkinCmd)
+	checkinCmd.run = func(args []string) error {
+		format, err := checkinFormat()
+		if err != nil {
+			return err
+		}
+		if len(args) == 0 {
+			return checkin("", "", format)
+		}
+		return checkin(args[0], strings.Join(args[1:], " "), format)
+	}
+
+	setupCmd := newCommand("setup", "", "Run setup wizard")
+	setupCmd.maxArgs = 0
+	setupCmd.run = func([]string) error { return runSetup() }
+
+	versionCmd := newCommand("version", "", "Print version")
+	versionCmd.maxArgs = 0
+	versionCmd.run = func([]string) error {
+		fmt.Println(version)
+		return nil
+	}
+
+	helpCmd := newCommand("help", "[command...]", "Show help for att or a command")
+	helpCmd.run = func(args []string) error {
+		target := root
+		for _, name := range args {
+			sub := target.lookup(name)
+			if sub == nil {
+				return usageErrorf("unknown command: %s", strings.Join(args, " "))
+			}
+			target = sub
+		}
+		target.printUsage(os.Stdout)
+		return nil
 	}
+
+	root.add(checkinCmd, newStatusCommand(), newLogCommand(), newStatsCommand(), newReportCommand(),
+		newExportCommand(), newImportCommand(), newScanCommitsCommand(), newSiteCommand(), newBadgeCommand(), newServeCommand(), newMetricsCommand(), newRemindCommand(), newNagCommand(), newWebhookCommand(), newHookCommand(), newTopicCommand(), newConfigCommand(), newProfileCommand(), newDoctorCommand(), setupCmd, newCompletionCommand(root), helpCmd, versionCmd)
+	return root
+}
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~") {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, path[1:])
+	}
+	return path
 }
 
+// getConfigPath returns the config file: --config, then $ATT_CONFIG, then
+// the active profile's.
 func getConfigPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".att/config.json")
+	if configOverride != "" {
+		return expandHome(configOverride)
+	}
+	if env := os.Getenv("ATT_CONFIG"); env != "" {
+		return expandHome(env)
+	}
+	return profileConfigPath(currentProfile())
 }
 
 func getDefaultDataPath() string {
-	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".att")
+	if dir := dataOverride(); dir != "" {
+		return dir
+	}
+	return dataDir(currentProfile())
 }
 
-func loadConfig() *model.Config {
+// loadConfig returns nil without error when no config file exists yet.
+func loadConfig() (*model.Config, error) {
+	if p := currentProfile(); !configFileOverridden() && p != defaultProfile && !profileNamePattern.MatchString(p) {
+		return nil, usageErrorf("invalid profile name %q", p)
+	}
 	configPath := getConfigPath()
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		// No config exists - need initial setup
-		return nil
+		return nil, nil
 	}
 
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		fmt.Printf("Error reading config: %v\n", err)
-		os.Exit(1)
+		return nil, configErrorf("reading config: %v", err)
+	}
+
+	if data, err = migrateConfig(configPath, data); err != nil {
+		return nil, err
 	}
 
 	var cfg model.Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
-		fmt.Printf("Error parsing config: %v\n", err)
-		os.Exit(1)
+		return nil, configErrorf("parsing config %s: %v", configPath, err)
 	}
 
-	// Initialize topics map if nil
+	// Hand-edited and legacy configs may use a ~-relative path, which the
+	// topics migration has already expanded.
+	cfg.DataPath = expandHome(cfg.DataPath)
+	if dir := dataOverride(); dir != "" {
+		cfg.DataPath = dir
+	}
+	if cfg.Topics, err = readTopics(cfg.DataPath); err != nil {
+		return nil, err
+	}
 	if cfg.Topics == nil {
 		cfg.Topics = make(map[string]*model.TopicConfig)
 	}
 
-	return &cfg
+	return &cfg, nil
+}
+
+// requireConfig is loadConfig for commands that cannot run before setup.
+func requireConfig() (*model.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		if p := currentProfile(); !configFileOverridden() && p != defaultProfile {
+			return nil, configErrorf("profile '%s' does not exist - create it with: att profile create %s", p, p)
+		}
+		return nil, configErrorf("no configuration found - run 'att setup' first")
+	}
+	return cfg, nil
+}
+
+// saveConfig writes the machine-local settings to config.json. Topics
+// live in the data repo; see topics.go.
+func saveConfig(cfg *model.Config) error {
+	path := getConfigPath()
+	// ATT_DATA overrides the data path in memory only; keep the stored one.
+	if dir := dataOverride(); dir != "" && cfg.DataPath == dir {
+		if configured := storedDataPath(path); configured != "" {
+			stored := *cfg
+			stored.DataPath = configured
+			cfg = &stored
+		}
+	}
+	return writeConfigFile(path, cfg)
+}
+
+// storedDataPath returns the data path in the config file at path, if any.
+// It is read afresh rather than remembered by loadConfig, which `att serve`
+// calls from concurrent requests.
+func storedDataPath(path string) string {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var stored struct {
+		DataPath string `json:"data_path"`
+	}
+	if json.Unmarshal(raw, &stored) != nil {
+		return ""
+	}
+	return stored.DataPath
 }
 
-func saveConfig(cfg *model.Config) {
+// writeConfigFile writes cfg's machine-local settings to path.
+func writeConfigFile(path string, cfg *model.Config) error {
+	cfg.Version = configSchemaVersion
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
-		fmt.Printf("Error saving config: %v\n", err)
-		os.Exit(1)
+		return configErrorf("saving config: %v", err)
 	}
 
-	if err := os.WriteFile(getConfigPath(), data, 0644); err != nil {
-		fmt.Printf("Error writing config: %v\n", err)
-		os.Exit(1)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return configErrorf("writing config: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return configErrorf("writing config: %v", err)
 	}
+	return nil
 }
 
 func runGit(repoPath string, args ...string) error {
@@ -136,13 +272,15 @@ func runGit(repoPath string, args ...string) error {
 	return cmd.Run()
 }
 
-func initRepo(cfg *model.Config) {
+func initRepo(cfg *model.Config) error {
 	dataPath := cfg.DataPath
-	os.MkdirAll(dataPath, 0755)
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return dataErrorf("creating data directory: %v", err)
+	}
 
 	gitDir := filepath.Join(dataPath, ".git")
 	if _, err := os.Stat(gitDir); err == nil {
-		return // Already initialized
+		return nil // Already initialized
 	}
 
 	runGit(dataPath, "init")
@@ -160,80 +298,127 @@ func initRepo(cfg *model.Config) {
 		}
 	}
 
-	saveData(dataPath, data)
+	if err := saveData(cfg, data); err != nil {
+		return err
+	}
 	runGit(dataPath, "add", ".")
 	runGit(dataPath, "commit", "-m", "Initial commit")
 
 	if cfg.SSHURL != "" {
 		runGit(dataPath, "remote", "add", "origin", cfg.SSHURL)
 	}
+	return nil
 }
 
-func syncRepo(dataPath string) {
-	runGit(dataPath, "pull", "origin", "main", "--rebase")
-	runGit(dataPath, "push", "origin", "main")
+func syncRepo(cfg *model.Config) {
+	dataPath := cfg.DataPath
+	var p hookPayload
+	if err := runGit(dataPath, "pull", "origin", "main", "--rebase"); err != nil {
+		p.Error = "pull: " + err.Error()
+	}
+	if err := runGit(dataPath, "push", "origin", "main"); err != nil && p.Error == "" {
+		p.Error = "push: " + err.Error()
+	}
+	runHooks(cfg, hookPostSync, p)
 }
 
-func loadData(dataPath string) *ProgressData {
-	progressPath := filepath.Join(dataPath, "progress.json")
-
-	data, err := os.ReadFile(progressPath)
-	if err != nil {
+func loadData(cfg *model.Config) (*ProgressData, error) {
+	if _, err := os.Stat(filepath.Join(cfg.DataPath, "progress.json")); err != nil {
 		// Create initial data if doesn't exist
 		progressData := &ProgressData{
 			Created: time.Now().Format(time.RFC3339),
 			Topics:  make(map[string]*TopicData),
 		}
-		saveData(dataPath, progressData)
-		return progressData
+		if err := saveData(cfg, progressData); err != nil {
+			return nil, err
+		}
+		return progressData, nil
+	}
+	return readData(cfg.DataPath)
+}
+
+// readData loads progress.json without creating, committing or syncing
+// anything, for commands that only display data. A missing file yields
+// empty data.
+func readData(dataPath string) (*ProgressData, error) {
+	progressPath := filepath.Join(dataPath, "progress.json")
+
+	data, err := os.ReadFile(progressPath)
+	if os.IsNotExist(err) {
+		return &ProgressData{Topics: make(map[string]*TopicData)}, nil
+	}
+	if err != nil {
+		return nil, dataErrorf("reading data: %v", err)
+	}
+
+	if data, err = migrateData(dataPath, progressPath, data); err != nil {
+		return nil, err
 	}
 
 	var progressData ProgressData
 	if err := json.Unmarshal(data, &progressData); err != nil {
-		fmt.Printf("Error parsing data: %v\n", err)
-		os.Exit(1)
+		return nil, dataErrorf("parsing data %s: %v", progressPath, err)
+	}
+	if progressData.Topics == nil {
+		progressData.Topics = make(map[string]*TopicData)
 	}
 
-	return &progressData
+	return &progressData, nil
 }
 
-func saveData(dataPath string, data *ProgressData) {
+func saveData(cfg *model.Config, data *ProgressData) error {
+	commitMsg := fmt.Sprintf("Update: %s", time.Now().Format("2006-01-02 15:04"))
+	return commitData(cfg, data, commitMsg)
+}
+
+// commitData writes progress.json to cfg's data repo and commits it with
+// message.
+func commitData(cfg *model.Config, data *ProgressData, message string) error {
+	dataPath := cfg.DataPath
 	progressPath := filepath.Join(dataPath, "progress.json")
 
+	if err := runHooks(cfg, hookPreSave, hookPayload{Message: message}); err != nil {
+		return err
+	}
+
+	data.Version = dataSchemaVersion
 	jsonData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		fmt.Printf("Error marshaling data: %v\n", err)
-		os.Exit(1)
+		return dataErrorf("marshaling data: %v", err)
 	}
 
 	if err := os.WriteFile(progressPath, jsonData, 0644); err != nil {
-		fmt.Printf("Error writing data: %v\n", err)
-		os.Exit(1)
+		return dataErrorf("writing data: %v", err)
 	}
 
 	runGit(dataPath, "add", "progress.json")
-	commitMsg := fmt.Sprintf("Update: %s", time.Now().Format("2006-01-02 15:04"))
-	runGit(dataPath, "commit", "-m", commitMsg)
+
+	// Badges are a convenience; failing to draw them never blocks a commit.
+	if cfg.Badges {
+		if err := writeBadges(dataPath, cfg, data); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			runGit(dataPath, "add", badgeDir)
+		}
+	}
+
+	runGit(dataPath, "commit", "-m", message)
+	runHooks(cfg, hookPostSave, hookPayload{Message: message})
+	return nil
 }
 
+// checkStreaks recomputes each topic's stored streak from its history, so
+// streaks that ended before yesterday read as 0. Days are local calendar
+// days, the same as in stats, badges, metrics and the site.
 func checkStreaks(data *ProgressData) {
-	today := time.Now().Truncate(24 * time.Hour)
-
+	now := time.Now()
 	for _, topicData := range data.Topics {
-		if topicData.LastDate != "" {
-			lastDate, _ := time.Parse(time.RFC3339, topicData.LastDate)
-			lastDate = lastDate.Truncate(24 * time.Hour)
-			yesterday := today.AddDate(0, 0, -1)
-
-			if lastDate.Before(yesterday) {
-				topicData.Streak = 0
-			}
-		}
+		topicData.Streak = currentStreak(topicData, now)
 	}
 }
 
 func getTodayProgress(data *ProgressData, topicID string) int {
-	today := time.Now().Truncate(24 * time.Hour)
+	today := dayKey(time.Now())
 	topicData := data.Topics[topicID]
 	if topicData == nil {
 		return 0
@@ -241,8 +426,8 @@ func getTodayProgress(data *ProgressData, topicID string) int {
 
 	count := 0
 	for _, entry := range topicData.History {
-		entryDate, _ := time.Parse(time.RFC3339, entry.Date)
-		if entryDate.Truncate(24 * time.Hour).Equal(today) {
+		entryDate, err := time.Parse(time.RFC3339, entry.Date)
+		if err == nil && dayKey(entryDate) == today {
 			count++
 		}
 	}
@@ -251,17 +436,22 @@ func getTodayProgress(data *ProgressData, topicID string) int {
 
 // Dashboard UI
 func NewDashboard() *Dashboard {
-	cfg := loadConfig()
-	if cfg == nil {
-		return &Dashboard{err: fmt.Errorf("no configuration found - run 'att setup' first")}
+	cfg, err := requireConfig()
+	if err != nil {
+		return &Dashboard{err: err}
 	}
 
-	initRepo(cfg)
-	data := loadData(cfg.DataPath)
+	if err := initRepo(cfg); err != nil {
+		return &Dashboard{err: err}
+	}
+	data, err := loadData(cfg)
+	if err != nil {
+		return &Dashboard{err: err}
+	}
 	checkStreaks(data)
 
 	if cfg.SSHURL != "" {
-		syncRepo(cfg.DataPath)
+		syncRepo(cfg)
 	}
 
 	return &Dashboard{
@@ -375,11 +565,11 @@ func (d *Dashboard) View() string {
 			totalText := ui.StatsStyle.Render(fmt.Sprintf("  Total: %d check-ins", topicData.TotalCheckIns))
 
 			// Today's check-ins
-			today := time.Now().Truncate(24 * time.Hour)
+			today := dayKey(time.Now())
 			var todayEntries []CheckIn
 			for _, entry := range topicData.History {
-				entryDate, _ := time.Parse(time.RFC3339, entry.Date)
-				if entryDate.Truncate(24 * time.Hour).Equal(today) {
+				entryDate, err := time.Parse(time.RFC3339, entry.Date)
+				if err == nil && dayKey(entryDate) == today {
 					todayEntries = append(todayEntries, entry)
 				}
 			}
@@ -413,51 +603,149 @@ func (d *Dashboard) View() string {
 	return ui.BorderStyle.Width(contentWidth).Render(content)
 }
 
-func showDashboard() {
+func showDashboard() error {
 	p := tea.NewProgram(NewDashboard(), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
+		return err
 	}
+	return nil
 }
 
 // Checkin command
-func checkin(topicID, remark string) {
-	cfg := loadConfig()
-	if cfg == nil {
-		fmt.Println("No configuration found. Run 'att setup' first.")
-		os.Exit(1)
-	}
-
-	topicCfg, exists := cfg.Topics[topicID]
-	if !exists {
-		fmt.Printf("Unknown topic: %s\n", topicID)
-		fmt.Println("\nAvailable topics:")
-		for id, tc := range cfg.Topics {
-			status := "enabled"
-			if !tc.Enabled {
-				status = "disabled"
+func checkin(query, remark, format string) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+
+	enabled := make(map[string]*model.TopicConfig)
+	for id, tc := range cfg.Topics {
+		if tc.Enabled {
+			enabled[id] = tc
+		}
+	}
+
+	topicID := ""
+	if query != "" {
+		// Prefer enabled topics, but fall back to all of them so that a
+		// disabled topic gets a helpful message rather than "unknown".
+		topicID, err = resolveTopic(enabled, query)
+		if err != nil && exitCode(err) == exitNotFound {
+			topicID, err = resolveTopic(cfg.Topics, query)
+		}
+		if exitCode(err) == exitNotFound {
+			fmt.Fprintln(os.Stderr, "Available topics:")
+			for id, tc := range cfg.Topics {
+				status := "enabled"
+				if !tc.Enabled {
+					status = "disabled"
+				}
+				fmt.Fprintf(os.Stderr, "  %s - %s %s (%s)\n", id, tc.Emoji, tc.Name, status)
 			}
-			fmt.Printf("  %s - %s %s (%s)\n", id, tc.Emoji, tc.Name, status)
 		}
-		os.Exit(1)
+		if err != nil {
+			return err
+		}
+		if !cfg.Topics[topicID].Enabled {
+			return configErrorf("topic '%s' is disabled. Enable it with: att topic enable %s", topicID, topicID)
+		}
+	} else if len(enabled) == 0 {
+		return configErrorf("no enabled topics - add one with: att topic add <id> <name> <goal> [emoji]")
 	}
 
-	if !topicCfg.Enabled {
-		fmt.Printf("Topic '%s' is disabled. Enable it with: att topic enable %s\n", topicID, topicID)
-		os.Exit(1)
+	if topicID == "" || remark == "" {
+		var ok bool
+		topicID, remark, ok, err = promptCheckin(enabled, topicID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Cancelled")
+			return nil
+		}
 	}
 
-	initRepo(cfg)
-	data := loadData(cfg.DataPath)
-	checkStreaks(data)
+	topicCfg := cfg.Topics[topicID]
+	topicData, progress, err := recordCheckin(cfg, topicID, remark)
+	if err != nil {
+		return err
+	}
 
-	if cfg.SSHURL != "" {
-		syncRepo(cfg.DataPath)
+	if format != formatText {
+		report := newCheckinReport(topicID, topicCfg, topicData, progress, remark)
+		header := append([]string{"date", "remark"}, topicStatusHeader...)
+		row := append([]string{report.Date, remark}, report.Topic.tsvRow()...)
+		return writeStructured(os.Stdout, format, report, header, [][]string{row})
+	}
+
+	// Show success message
+	showCheckinSuccess(topicCfg, topicData, progress, remark)
+	return nil
+}
+
+// recordCheckin appends a check-in for topicID, updates its streak, and
+// commits and syncs the data repo. It returns the updated topic data and
+// today's check-in count. The check-in hooks run outside the data lock so
+// they are free to call att themselves.
+func recordCheckin(cfg *model.Config, topicID, remark string) (*TopicData, int, error) {
+	topicCfg := cfg.Topics[topicID]
+
+	if err := initRepo(cfg); err != nil {
+		return nil, 0, err
+	}
+	before, err := readData(cfg.DataPath)
+	if err != nil {
+		return nil, 0, err
+	}
+	checkStreaks(before)
+	status := newTopicStatus(topicID, topicCfg, before)
+	if err := runHooks(cfg, hookPreCheckin, hookPayload{Topic: &status, Remark: remark}); err != nil {
+		return nil, 0, err
 	}
 
 	now := time.Now()
-	today := now.Truncate(24 * time.Hour)
+	topicData, progress, prevStreak, err := appendCheckin(cfg, topicID, remark, now)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	notifyWebhooks(cfg, checkinEvents(topicID, topicCfg, topicData, progress, remark, prevStreak, now))
+	after := newCheckinReport(topicID, topicCfg, topicData, progress, remark).Topic
+	runHooks(cfg, hookPostCheckin, hookPayload{Topic: &after, Remark: remark})
+	return topicData, progress, nil
+}
+
+// appendCheckin does the locked part of recordCheckin. It also returns the
+// topic's streak as stored before the check-in.
+func appendCheckin(cfg *model.Config, topicID, remark string, now time.Time) (*TopicData, int, int, error) {
+	unlock, err := lockData(cfg.DataPath)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	defer unlock()
+
+	// Pull first, so the check-in builds on other machines' history and
+	// topics rather than overwriting them.
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
+	}
+	if err := refreshTopics(cfg); err != nil {
+		return nil, 0, 0, err
+	}
+	topicCfg := cfg.Topics[topicID]
+	if topicCfg == nil {
+		return nil, 0, 0, notFoundErrorf("topic '%s' was removed on another machine", topicID)
+	}
+	data, err := loadData(cfg)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	prevStreak := 0
+	if td := data.Topics[topicID]; td != nil {
+		prevStreak = td.Streak
+	}
+	checkStreaks(data)
+
 	topicData := data.Topics[topicID]
 	if topicData == nil {
 		topicData = &TopicData{
@@ -476,31 +764,17 @@ func checkin(topicID, remark string) {
 
 	// Update streak
 	currentProgress := getTodayProgress(data, topicID)
-	if currentProgress == 1 {
-		if topicData.LastDate != "" {
-			lastDate, _ := time.Parse(time.RFC3339, topicData.LastDate)
-			lastDate = lastDate.Truncate(24 * time.Hour)
-			yesterday := today.AddDate(0, 0, -1)
-
-			if lastDate.Equal(yesterday) || lastDate.Equal(today) {
-				topicData.Streak++
-			} else {
-				topicData.Streak = 1
-			}
-		} else {
-			topicData.Streak = 1
-		}
-	}
+	topicData.Streak = currentStreak(topicData, now)
 	topicData.LastDate = now.Format(time.RFC3339)
 
-	saveData(cfg.DataPath, data)
+	if err := saveData(cfg, data); err != nil {
+		return nil, 0, 0, err
+	}
 
 	if cfg.SSHURL != "" {
-		syncRepo(cfg.DataPath)
+		syncRepo(cfg)
 	}
-
-	// Show success message
-	showCheckinSuccess(topicCfg, topicData, currentProgress, remark)
+	return topicData, currentProgress, prevStreak, nil
 }
 
 func showCheckinSuccess(cfg *model.TopicConfig, data *TopicData, progress int, remark string) {
@@ -559,58 +833,77 @@ func showCheckinSuccess(cfg *model.TopicConfig, data *TopicData, progress int, r
 }
 
 // Topic management
-func handleTopicCommand() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: att topic <command> [args]")
-		fmt.Println("\nCommands:")
-		fmt.Println("  add <id> <n> <goal> [emoji]  - Add new topic")
-		fmt.Println("  remove <id>                   - Remove topic")
-		fmt.Println("  enable <id>                   - Enable topic")
-		fmt.Println("  disable <id>                  - Disable topic")
-		fmt.Println("  list                          - List all topics")
-		os.Exit(1)
-	}
-
-	subCmd := os.Args[2]
-
-	switch subCmd {
-	case "add", "+":
-		topicAdd()
-	case "remove", "rm", "delete":
-		topicRemove()
-	case "enable":
-		topicEnable(true)
-	case "disable":
-		topicDisable(false)
-	case "list", "ls":
-		topicList()
-	default:
-		fmt.Printf("Unknown topic command: %s\n", subCmd)
-		os.Exit(1)
+func newTopicCommand() *command {
+	topicCmd := newCommand("topic", "", "Manage topics")
+	topicCmd.aliases = []string{"t"}
+
+	addCmd := newCommand("add", "<id> <name> <goal> [emoji]", "Add new topic")
+	addCmd.aliases = []string{"+"}
+	addCmd.minArgs, addCmd.maxArgs = 3, 4
+	addCmd.examples = []string{
+		"att topic add dsa 'DSA Practice' 3 '💻'",
+		"att topic add reading 'Daily Reading' 1 '📚'",
+		"att t + exercise 'Exercise' 1 '💪'",
+	}
+	addCmd.run = func(args []string) error {
+		dailyGoal, err := parseGoal(args[2])
+		if err != nil {
+			return err
+		}
+		emoji := "📌"
+		if len(args) > 3 {
+			emoji = args[3]
+		}
+		return topicAdd(args[0], args[1], dailyGoal, emoji)
+	}
+
+	removeCmd := newCommand("remove", "<id>", "Remove topic")
+	removeCmd.aliases = []string{"rm", "delete"}
+	removeCmd.minArgs, removeCmd.maxArgs = 1, 1
+	removeCmd.topicArg = anyTopicArg
+	yes := removeCmd.flags.Bool("yes", false, "skip the confirmation prompt")
+	removeCmd.run = func(args []string) error { return topicRemove(args[0], *yes) }
+
+	enableCmd := newCommand("enable", "<id>", "Enable topic")
+	enableCmd.minArgs, enableCmd.maxArgs = 1, 1
+	enableCmd.topicArg = anyTopicArg
+	enableCmd.run = func(args []string) error { return topicEnable(args[0], true) }
+
+	disableCmd := newCommand("disable", "<id>", "Disable topic (pause tracking)")
+	disableCmd.minArgs, disableCmd.maxArgs = 1, 1
+	disableCmd.topicArg = anyTopicArg
+	disableCmd.run = func(args []string) error { return topicEnable(args[0], false) }
+
+	listCmd := newCommand("list", "", "List all topics")
+	listCmd.aliases = []string{"ls"}
+	listCmd.maxArgs = 0
+	listFormat := addFormatFlags(listCmd)
+	listCmd.run = func([]string) error {
+		format, err := listFormat()
+		if err != nil {
+			return err
+		}
+		return topicList(format)
 	}
+
+	topicCmd.add(addCmd, removeCmd, enableCmd, disableCmd, listCmd)
+	return topicCmd
 }
 
-func topicAdd() {
-	if len(os.Args) < 6 {
-		fmt.Println("Usage: att topic add <id> <n> <goal> [emoji]")
-		fmt.Println("\nExamples:")
-		fmt.Println("  att topic add dsa 'DSA Practice' 3 '💻'")
-		fmt.Println("  att topic add reading 'Daily Reading' 1 '📚'")
-		fmt.Println("  att t + exercise 'Exercise' 1 '💪'")
-		os.Exit(1)
+// parseGoal validates a daily goal given on the command line.
+func parseGoal(s string) (int, error) {
+	goal, err := strconv.Atoi(s)
+	if err != nil || goal <= 0 {
+		return 0, usageErrorf("invalid goal %q: must be a positive whole number", s)
 	}
+	return goal, nil
+}
 
-	topicID := os.Args[3]
-	name := os.Args[4]
-	var dailyGoal int
-	fmt.Sscanf(os.Args[5], "%d", &dailyGoal)
-
-	emoji := "📌"
-	if len(os.Args) > 6 {
-		emoji = os.Args[6]
+func topicAdd(topicID, name string, dailyGoal int, emoji string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
 	}
-
-	cfg := loadConfig()
 	if cfg == nil {
 		cfg = &model.Config{
 			DataPath: getDefaultDataPath(),
@@ -619,130 +912,203 @@ func topicAdd() {
 	}
 
 	if _, exists := cfg.Topics[topicID]; exists {
-		fmt.Printf("Topic '%s' already exists\n", topicID)
-		os.Exit(1)
+		return usageErrorf("topic '%s' already exists", topicID)
 	}
 
-	cfg.Topics[topicID] = &model.TopicConfig{
+	topicCfg := &model.TopicConfig{
 		Name:      name,
 		DailyGoal: dailyGoal,
 		Emoji:     emoji,
 		Enabled:   true,
 	}
-
-	saveConfig(cfg)
+	status := topicStatus{ID: topicID, Name: name, Emoji: emoji, Enabled: true, DailyGoal: dailyGoal}
+	if err := runHooks(cfg, hookPreTopicAdd, hookPayload{Topic: &status}); err != nil {
+		return err
+	}
 
 	// Update data if repo exists
 	if _, err := os.Stat(filepath.Join(cfg.DataPath, ".git")); err == nil {
-		data := loadData(cfg.DataPath)
-		data.Topics[topicID] = &TopicData{
-			Name:    name,
-			History: []CheckIn{},
+		if err := addTopicToRepo(cfg, topicID, topicCfg); err != nil {
+			return err
 		}
-		saveData(cfg.DataPath, data)
-
-		if cfg.SSHURL != "" {
-			syncRepo(cfg.DataPath)
+		// Writes config.json, if this is the first topic.
+		if err := saveConfig(cfg); err != nil {
+			return err
 		}
 	} else {
-		initRepo(cfg)
+		cfg.Topics[topicID] = topicCfg
+		if err := saveTopics(cfg); err != nil {
+			return err
+		}
+		if err := saveConfig(cfg); err != nil {
+			return err
+		}
+		if err := initRepo(cfg); err != nil {
+			return err
+		}
 	}
 
+	runHooks(cfg, hookPostTopicAdd, hookPayload{Topic: &status})
 	fmt.Printf("✓ Topic added: %s %s (goal: %d/day)\n", emoji, name, dailyGoal)
+	return nil
 }
 
-func topicRemove() {
-	if len(os.Args) < 4 {
-		fmt.Println("Usage: att topic remove <id>")
-		os.Exit(1)
+// addTopicToRepo adds a topic to the data repo's topics and history in one
+// commit, after pulling so that topics added elsewhere are kept.
+func addTopicToRepo(cfg *model.Config, topicID string, topicCfg *model.TopicConfig) error {
+	unlock, err := lockData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
+	}
+	if err := refreshTopics(cfg); err != nil {
+		return err
+	}
+	if _, exists := cfg.Topics[topicID]; exists {
+		return usageErrorf("topic '%s' already exists", topicID)
+	}
+	previous := maps.Clone(cfg.Topics)
+	cfg.Topics[topicID] = topicCfg
+	if _, err := stageTopics(cfg.DataPath, cfg.Topics); err != nil {
+		return err
 	}
 
-	topicID := os.Args[3]
-	cfg := loadConfig()
-	if cfg == nil {
-		fmt.Println("No configuration found")
-		os.Exit(1)
+	data, err := loadData(cfg)
+	if err != nil {
+		stageTopics(cfg.DataPath, previous)
+		return err
+	}
+	data.Topics[topicID] = &TopicData{
+		Name:    topicCfg.Name,
+		History: []CheckIn{},
+	}
+	if err := saveData(cfg, data); err != nil {
+		stageTopics(cfg.DataPath, previous)
+		return err
 	}
 
-	if _, exists := cfg.Topics[topicID]; !exists {
-		fmt.Printf("Topic '%s' not found\n", topicID)
-		os.Exit(1)
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
 	}
+	return nil
+}
 
-	fmt.Printf("Remove topic '%s'? This will delete all history. (y/n): ", topicID)
-	var response string
-	fmt.Scanln(&response)
+func topicRemove(topicID string, skipConfirm bool) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
 
-	if response != "y" && response != "yes" {
-		fmt.Println("Cancelled")
-		return
+	if _, exists := cfg.Topics[topicID]; !exists {
+		return notFoundErrorf("topic '%s' not found", topicID)
 	}
 
-	delete(cfg.Topics, topicID)
-	saveConfig(cfg)
+	if !skipConfirm {
+		fmt.Printf("Remove topic '%s'? This will delete all history. (y/n): ", topicID)
+		var response string
+		fmt.Scanln(&response)
 
-	// Remove from data
+		if response != "y" && response != "yes" {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	// Remove from topics and data in one commit
 	if _, err := os.Stat(filepath.Join(cfg.DataPath, ".git")); err == nil {
-		data := loadData(cfg.DataPath)
+		unlock, err := lockData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		if cfg.SSHURL != "" {
+			syncRepo(cfg)
+		}
+		if err := refreshTopics(cfg); err != nil {
+			return err
+		}
+		previous := maps.Clone(cfg.Topics)
+		delete(cfg.Topics, topicID)
+		if _, err := stageTopics(cfg.DataPath, cfg.Topics); err != nil {
+			return err
+		}
+		data, err := loadData(cfg)
+		if err != nil {
+			stageTopics(cfg.DataPath, previous)
+			return err
+		}
 		delete(data.Topics, topicID)
-		saveData(cfg.DataPath, data)
+		if err := saveData(cfg, data); err != nil {
+			stageTopics(cfg.DataPath, previous)
+			return err
+		}
 
 		if cfg.SSHURL != "" {
-			syncRepo(cfg.DataPath)
+			syncRepo(cfg)
+		}
+	} else {
+		delete(cfg.Topics, topicID)
+		if err := saveTopics(cfg); err != nil {
+			return err
 		}
 	}
 
 	fmt.Printf("✓ Topic '%s' removed\n", topicID)
+	return nil
 }
 
-func topicEnable(enable bool) {
-	if len(os.Args) < 4 {
-		action := "enable"
-		if !enable {
-			action = "disable"
-		}
-		fmt.Printf("Usage: att topic %s <id>\n", action)
-		os.Exit(1)
-	}
-
-	topicID := os.Args[3]
-	cfg := loadConfig()
-	if cfg == nil {
-		fmt.Println("No configuration found")
-		os.Exit(1)
-	}
-
-	topicCfg, exists := cfg.Topics[topicID]
-	if !exists {
-		fmt.Printf("Topic '%s' not found\n", topicID)
-		os.Exit(1)
+func topicEnable(topicID string, enable bool) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
 	}
 
-	topicCfg.Enabled = enable
-	saveConfig(cfg)
-
 	status := "enabled"
 	if !enable {
 		status = "disabled"
 	}
+	err = updateTopics(cfg, fmt.Sprintf("Topics: %s %s", status, topicID), func(topics map[string]*model.TopicConfig) error {
+		topicCfg, exists := topics[topicID]
+		if !exists {
+			return notFoundErrorf("topic '%s' not found", topicID)
+		}
+		topicCfg.Enabled = enable
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 	fmt.Printf("✓ Topic '%s' %s\n", topicID, status)
+	return nil
 }
 
-func topicDisable(enable bool) {
-	topicEnable(false)
-}
+func topicList(format string) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
 
-func topicList() {
-	cfg := loadConfig()
-	if cfg == nil {
-		fmt.Println("No configuration found")
-		os.Exit(1)
+	if format != formatText {
+		data, err := readData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+		checkStreaks(data)
+		statuses := topicStatuses(cfg, data)
+		var rows [][]string
+		for _, st := range statuses {
+			rows = append(rows, st.tsvRow())
+		}
+		return writeStructured(os.Stdout, format, statuses, topicStatusHeader, rows)
 	}
 
 	if len(cfg.Topics) == 0 {
 		fmt.Println("No topics configured")
-		fmt.Println("\nAdd a topic with: att topic add <id> <n> <goal> [emoji]")
-		return
+		fmt.Println("\nAdd a topic with: att topic add <id> <name> <goal> [emoji]")
+		return nil
 	}
 
 	fmt.Println("\nConfigured Topics:")
@@ -761,43 +1127,89 @@ func topicList() {
 			statusText, id, topic.Emoji, topic.Name, topic.DailyGoal)
 	}
 	fmt.Println()
+	return nil
 }
 
 // Config management
-func handleConfigCommand() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: att config <command>")
-		fmt.Println("\nCommands:")
-		fmt.Println("  show              - Show current configuration")
-		fmt.Println("  set-path <path>   - Set data directory path")
-		fmt.Println("  set-remote <url>  - Set Git remote URL")
-		os.Exit(1)
-	}
-
-	subCmd := os.Args[2]
-
-	switch subCmd {
-	case "show":
-		configShow()
-	case "set-path":
-		configSetPath()
-	case "set-remote":
-		configSetRemote()
-	default:
-		fmt.Printf("Unknown config command: %s\n", subCmd)
-		os.Exit(1)
+func newConfigCommand() *command {
+	configCmd := newCommand("config", "", "Manage configuration")
+
+	showCmd := newCommand("show", "", "Show current configuration")
+	showCmd.maxArgs = 0
+	showFormat := addFormatFlags(showCmd)
+	showCmd.run = func([]string) error {
+		format, err := showFormat()
+		if err != nil {
+			return err
+		}
+		return configShow(format)
+	}
+
+	setPathCmd := newCommand("set-path", "<path>", "Set data directory path")
+	setPathCmd.minArgs, setPathCmd.maxArgs = 1, 1
+	setPathCmd.examples = []string{
+		"att config set-path ~/.att",
+		"att config set-path ~/Documents/tracking",
+	}
+	setPathCmd.run = func(args []string) error { return configSetPath(args[0]) }
+
+	setRemoteCmd := newCommand("set-remote", "<url>", "Set Git remote URL")
+	setRemoteCmd.minArgs, setRemoteCmd.maxArgs = 1, 1
+	setRemoteCmd.examples = []string{
+		"att config set-remote git@github.com:user/tracking.git",
+		"att config set-remote ''  (to remove)",
 	}
+	setRemoteCmd.run = func(args []string) error { return configSetRemote(args[0]) }
+
+	setBadgesCmd := newCommand("set-badges", "<on|off>", "Regenerate SVG badges in the data repo on every commit")
+	setBadgesCmd.minArgs, setBadgesCmd.maxArgs = 1, 1
+	setBadgesCmd.run = func(args []string) error { return configSetBadges(args[0]) }
+
+	configCmd.add(showCmd, setPathCmd, setRemoteCmd, setBadgesCmd)
+	return configCmd
 }
 
-func configShow() {
-	cfg := loadConfig()
+func configShow(format string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if cfg == nil && format != formatText {
+		return configErrorf("no configuration found - run 'att setup' first")
+	}
 	if cfg == nil {
 		fmt.Println("No configuration found. Run 'att setup' to create one.")
-		return
+		return nil
+	}
+
+	if format != formatText {
+		data, err := readData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+		checkStreaks(data)
+		report := configReport{
+			Profile:    currentProfile(),
+			ConfigFile: getConfigPath(),
+			DataPath:   cfg.DataPath,
+			Remote:     cfg.SSHURL,
+			Badges:     cfg.Badges,
+			Topics:     topicStatuses(cfg, data),
+		}
+		rows := [][]string{
+			{"profile", report.Profile},
+			{"config_file", report.ConfigFile},
+			{"data_path", report.DataPath},
+			{"remote", report.Remote},
+			{"badges", strconv.FormatBool(report.Badges)},
+			{"topics", strconv.Itoa(len(report.Topics))},
+		}
+		return writeStructured(os.Stdout, format, report, []string{"key", "value"}, rows)
 	}
 
 	fmt.Println("\n📋 Current Configuration")
 	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Profile:     %s\n", currentProfile())
 	fmt.Printf("Data Path:   %s\n", cfg.DataPath)
 
 	if cfg.SSHURL != "" {
@@ -805,6 +1217,9 @@ func configShow() {
 	} else {
 		fmt.Println("Git Remote:  Not configured")
 	}
+	if cfg.Badges {
+		fmt.Printf("Badges:      %s\n", filepath.Join(cfg.DataPath, badgeDir))
+	}
 
 	fmt.Printf("Topics:      %d configured\n", len(cfg.Topics))
 	fmt.Println()
@@ -823,26 +1238,16 @@ func configShow() {
 
 	fmt.Printf("Config file: %s\n", getConfigPath())
 	fmt.Println()
+	return nil
 }
 
-func configSetPath() {
-	if len(os.Args) < 4 {
-		fmt.Println("Usage: at config set-path <path>")
-		fmt.Println("\nExamples:")
-		fmt.Println("  att config set-path ~/.att")
-		fmt.Println("  att config set-path ~/Documents/tracking")
-		os.Exit(1)
-	}
-
-	newPath := os.Args[3]
+func configSetPath(newPath string) error {
+	newPath = expandHome(newPath)
 
-	// Expand ~ to home directory
-	if strings.HasPrefix(newPath, "~") {
-		home, _ := os.UserHomeDir()
-		newPath = filepath.Join(home, newPath[1:])
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
 	}
-
-	cfg := loadConfig()
 	if cfg == nil {
 		cfg = &model.Config{
 			DataPath: newPath,
@@ -851,30 +1256,35 @@ func configSetPath() {
 	} else {
 		cfg.DataPath = newPath
 	}
+	// A repo that already has topics, such as a clone, keeps them;
+	// otherwise the current ones move with the data.
+	topics, err := readTopics(newPath)
+	if err != nil {
+		return err
+	}
+	if topics != nil {
+		cfg.Topics = topics
+	} else if err := saveTopics(cfg); err != nil {
+		return err
+	}
 
-	saveConfig(cfg)
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
 	fmt.Printf("✓ Data path updated: %s\n", newPath)
+	return nil
 }
 
-func configSetRemote() {
-	if len(os.Args) < 4 {
-		fmt.Println("Usage: att config set-remote <url>")
-		fmt.Println("\nExamples:")
-		fmt.Println("  att config set-remote git@github.com:user/tracking.git")
-		fmt.Println("  att config set-remote ''  (to remove)")
-		os.Exit(1)
-	}
-
-	remoteURL := os.Args[3]
-
-	cfg := loadConfig()
-	if cfg == nil {
-		fmt.Println("No configuration found. Run 'att setup' first.")
-		os.Exit(1)
+func configSetRemote(remoteURL string) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
 	}
 
 	cfg.SSHURL = remoteURL
-	saveConfig(cfg)
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
 
 	if remoteURL == "" {
 		fmt.Println("✓ Git remote removed")
@@ -887,16 +1297,62 @@ func configSetRemote() {
 			runGit(cfg.DataPath, "remote", "add", "origin", remoteURL)
 		}
 	}
+	return nil
+}
+
+func configSetBadges(value string) error {
+	var on bool
+	switch strings.ToLower(value) {
+	case "on", "true", "yes":
+		on = true
+	case "off", "false", "no":
+	default:
+		return usageErrorf("expected on or off, got %q", value)
+	}
+
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	cfg.Badges = on
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	if !on {
+		fmt.Println("✓ Badges will no longer be regenerated")
+		return nil
+	}
+	unlock, err := lockData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := loadData(cfg)
+	if err != nil {
+		return err
+	}
+	if err := commitData(cfg, data, "Badges: regenerate"); err != nil {
+		return err
+	}
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
+	}
+	fmt.Printf("✓ Badges are kept up to date in %s\n", filepath.Join(cfg.DataPath, badgeDir))
+	return nil
 }
 
 // Setup wizard
-func runSetup() {
+func runSetup() error {
 	fmt.Println()
 	fmt.Println("🎯 Activity Tracker Setup")
 	fmt.Println(strings.Repeat("=", 60))
 	fmt.Println()
 
-	cfg := loadConfig()
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
 	if cfg == nil {
 		cfg = &model.Config{
 			DataPath: getDefaultDataPath(),
@@ -909,11 +1365,7 @@ func runSetup() {
 	var dataPath string
 	fmt.Scanln(&dataPath)
 	if dataPath != "" {
-		if strings.HasPrefix(dataPath, "~") {
-			home, _ := os.UserHomeDir()
-			dataPath = filepath.Join(home, dataPath[1:])
-		}
-		cfg.DataPath = dataPath
+		cfg.DataPath = expandHome(dataPath)
 	}
 
 	// Git remote
@@ -929,10 +1381,14 @@ func runSetup() {
 	}
 
 	// Save config
-	saveConfig(cfg)
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
 
 	// Initialize repo
-	initRepo(cfg)
+	if err := initRepo(cfg); err != nil {
+		return err
+	}
 
 	fmt.Println()
 	fmt.Println("✓ Setup complete!")
@@ -950,25 +1406,78 @@ func runSetup() {
 	} else {
 		fmt.Println("Run 'att' to see your dashboard")
 	}
+	return nil
 }
 
-func showHelp() {
-	help := `
+const helpText = `
 AHDHD - Tracker Tool
 
 A Git-backed progress tracker for your daily goals.
 
 USAGE:
-  att                                  Show dashboard
-  att checkin <topic> <remark>         Record a check-in
+  att [--config <path>]                Show dashboard
+  att [--profile <name>] <command>     Use a profile's topics and data
+  att checkin [topic] [remark]         Record a check-in
+  att status [--template <tmpl>]       Show today's progress for every topic
+  att log [topic] [flags]              List past check-ins grouped by day
+  att stats [topic] [--period <p>]     Completion, activity and streak analytics
+  att report [--week|--month]          Markdown review of the week or month
+  att export csv [topic]               Export check-ins as CSV
+  att export ics [topic]               Export check-ins as an iCalendar file
+  att import csv <file>                Import check-ins from CSV
+  att import loop <zip|dir|db>         Import from Loop Habit Tracker
+  att import habitica <file.json>      Import from a Habitica data export
+  att import habits <file>             Import habit,date[,value[,note]] rows
+  att site build <dir>                 Generate a static HTML progress site
+  att badge <topic> [--kind k]         Generate an SVG streak/total/week badge
+  att serve [--addr host:port]         Serve a local HTTP/JSON API
+  att metrics <serve|print>            Export habit metrics for Prometheus
+  att remind <set|daemon|snooze|...>   Desktop reminders for unmet goals
+  att nag [--mail <addr>]              Warn about streaks that break tonight (for cron)
+  att webhook <add|list|remove|test>   Send events to webhooks
+  att hook <list|set|unset>            Manage hook scripts (see docs/hooks.md)
+  att hook install <repo> --topic <t>  Check in on every commit to another repo
+  att scan-commits <repo> --topic <t>  Backfill check-ins from a repo's git log
   att topic <command> [args]           Manage topics
   att config <command> [args]          Manage configuration
+  att profile <list|create|switch>     Keep separate topics, e.g. work and personal
   att setup                            Run setup wizard
-  att help                             Show this help
+  att doctor [--fix] [--remote]        Check config, data and Git for problems
+  att completion <bash|zsh|fish>       Print a shell completion script
+  att help [command]                   Show this help, or help for a command
+  att <command> --help                 Show help for a command
+
+GLOBAL FLAGS:
+  --config <path>                      Use an alternate config file
+  --profile <name>                     Use a profile (default: $ATT_PROFILE, then 'att profile switch')
+  --version, -v                        Print version
+
+LOG FLAGS:
+  --since <day>, --until <day>         Limit to a date range (YYYY-MM-DD, yesterday, 7d)
+  --limit <n>                          Show only the newest n check-ins
+  --grep <regexp>                      Filter by remark
+
+STATS FLAGS:
+  --period <p>                         30d (default), 4w, week, month or year
+
+REPORT FLAGS:
+  --previous                           Review the previous complete week/month
+  --out <file>                         Write Markdown to a file
+
+IMPORT FLAGS:
+  --map field=Column,...               Map CSV columns (topic, name, timestamp, remark, amount)
+  --topic <id>                         Import every row into one topic
+  --create-topics                      Create missing topics with an inferred goal
+                                       (default for loop, habitica and habits)
+  --dry-run                            Preview without writing anything
+
+OUTPUT FLAGS (checkin, status, log, stats, topic list, config show):
+  --json                               Shorthand for --format=json
+  --format <text|json|yaml|tsv>        Machine-readable output (see docs/output.md)
 
 TOPIC COMMANDS:
-  att topic add <id> <n> <goal> [emoji]   Add new topic
-  att topic remove <id>                    Remove topic
+  att topic add <id> <name> <goal> [emoji] Add new topic
+  att topic remove [--yes] <id>            Remove topic
   att topic enable <id>                    Enable topic
   att topic disable <id>                   Disable topic (pause tracking)
   att topic list                           List all topics
@@ -977,6 +1486,7 @@ CONFIG COMMANDS:
   att config show                      Show configuration
   att config set-path <path>           Set data directory
   att config set-remote <url>          Set Git remote URL
+  att config set-badges <on|off>       Regenerate badges/ in the data repo on commit
 
 EXAMPLES:
   # Add topics
@@ -986,6 +1496,8 @@ EXAMPLES:
   # Check in
   att checkin dsa "Solved two sum problem"
   att c reading "Read 30 pages"  # 'c' is short for checkin
+  att c ds "Graphs"              # topics match by prefix or fuzzy
+  att checkin                    # pick a topic interactively
 
   # Manage topics
   att topic disable dsa              # Pause tracking
@@ -997,10 +1509,28 @@ EXAMPLES:
   att config set-path ~/tracking     # Change data location
   att config set-remote git@...      # Set Git remote
 
+EXIT CODES:
+  0  Success
+  1  Unexpected failure
+  2  Invalid flags or arguments
+  3  Configuration missing or invalid
+  4  Topic not found
+  5  Data file or Git repository error
+  6  att nag: a streak breaks at midnight
+  7  att doctor: problems found
+
 FILES:
-  Config:  ~/.att_config.json
-  Data:    ~/.att/ (or custom path)
+  Config:   $XDG_CONFIG_HOME/att/config.json (~/.config/att/config.json)
+  Data:     $XDG_DATA_HOME/att/ (~/.local/share/att/), or the configured path;
+            topics.json and progress.json there are committed and synced
+  Profiles: $XDG_CONFIG_HOME/att/profiles/<name>.json, with data in
+            $XDG_DATA_HOME/att-<name>/
+  Config and data that older versions kept in ~/.att/ are still used; the
+  config is moved to the new location on first run.
+
+ENVIRONMENT:
+  ATT_CONFIG   Config file to use, like --config
+  ATT_DATA     Data directory to use instead of the configured one
+  ATT_PROFILE  Profile to use, like --profile
 
 For more info, visit: (https://github.com/skydev-x/att)`
-	fmt.Println(help)
-}
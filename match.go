@@ -0,0 +1,112 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"att/model"
+)
+
+// resolveTopic finds the topic a user meant by query. It tries, in order,
+// an exact ID, a case-insensitive ID or name, a unique ID or name prefix,
+// and finally a unique fuzzy match, so "ds" resolves to "dsa".
+func resolveTopic(topics map[string]*model.TopicConfig, query string) (string, error) {
+	if _, ok := topics[query]; ok {
+		return query, nil
+	}
+
+	q := strings.ToLower(query)
+	matchers := []func(id string, t *model.TopicConfig) bool{
+		func(id string, t *model.TopicConfig) bool {
+			return strings.ToLower(id) == q || strings.ToLower(t.Name) == q
+		},
+		func(id string, t *model.TopicConfig) bool {
+			return strings.HasPrefix(strings.ToLower(id), q) || strings.HasPrefix(strings.ToLower(t.Name), q)
+		},
+		func(id string, t *model.TopicConfig) bool {
+			return topicScore(query, id, t) > 0
+		},
+	}
+
+	for _, matches := range matchers {
+		var found []string
+		for id, t := range topics {
+			if matches(id, t) {
+				found = append(found, id)
+			}
+		}
+		switch {
+		case len(found) == 1:
+			return found[0], nil
+		case len(found) > 1:
+			sort.Strings(found)
+			return "", usageErrorf("topic %q is ambiguous: matches %s", query, strings.Join(found, ", "))
+		}
+	}
+
+	return "", notFoundErrorf("unknown topic: %s", query)
+}
+
+// rankTopics orders topic IDs by how well they match query. Topics that
+// do not match at all are dropped; an empty query keeps every topic.
+func rankTopics(topics map[string]*model.TopicConfig, query string) []string {
+	scores := make(map[string]int)
+	var ids []string
+	for id, t := range topics {
+		score := 1
+		if query != "" {
+			score = topicScore(query, id, t)
+		}
+		if score > 0 {
+			scores[id] = score
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] != scores[ids[j]] {
+			return scores[ids[i]] > scores[ids[j]]
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+func topicScore(query, id string, t *model.TopicConfig) int {
+	return max(fuzzyScore(query, id), fuzzyScore(query, t.Name))
+}
+
+// fuzzyScore reports how well query matches target as a case-insensitive
+// subsequence, or 0 if it does not. Consecutive characters and characters
+// at the start of a word score higher.
+func fuzzyScore(query, target string) int {
+	if query == "" {
+		return 0
+	}
+	q := []rune(strings.ToLower(query))
+	t := []rune(strings.ToLower(target))
+
+	score, qi := 0, 0
+	prevMatch := -2
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if r != q[qi] {
+			continue
+		}
+		score++
+		if ti == prevMatch+1 {
+			score += 2
+		}
+		if ti == 0 || !unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1]) {
+			score += 3
+		}
+		prevMatch = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0
+	}
+	return score
+}
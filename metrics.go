@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"att/model"
+)
+
+func newMetricsCommand() *command {
+	metricsCmd := newCommand("metrics", "", "Export habit metrics for Prometheus")
+
+	serveCmd := newCommand("serve", "", "Serve /metrics for Prometheus to scrape")
+	serveCmd.maxArgs = 0
+	addr := serveCmd.flags.String("addr", "127.0.0.1:9787", "`address` to listen on")
+	token := serveCmd.flags.String("token", "", "require this bearer `token` (default: none)")
+	serveCmd.examples = []string{
+		"att metrics serve --addr 0.0.0.0:9787",
+	}
+	serveCmd.run = func([]string) error {
+		if _, err := requireConfig(); err != nil {
+			return err
+		}
+		var handler http.Handler = http.HandlerFunc(serveMetrics)
+		if *token != "" {
+			handler = requireToken(*token, handler)
+		}
+		mux := http.NewServeMux()
+		mux.Handle("GET /metrics", handler)
+		srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		fmt.Printf("Serving metrics on http://%s/metrics (Ctrl+C to stop)\n", *addr)
+		return srv.ListenAndServe()
+	}
+
+	printCmd := newCommand("print", "", "Print metrics once, e.g. for the node exporter's textfile collector")
+	printCmd.maxArgs = 0
+	printCmd.examples = []string{
+		"att metrics print > /var/lib/node_exporter/att.prom",
+	}
+	printCmd.run = func([]string) error {
+		cfg, err := requireConfig()
+		if err != nil {
+			return err
+		}
+		data, err := readData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+		return writeMetrics(os.Stdout, cfg, data, time.Now())
+	}
+
+	metricsCmd.add(serveCmd, printCmd)
+	return metricsCmd
+}
+
+func serveMetrics(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requireConfig()
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetrics(w, cfg, data, time.Now())
+}
+
+// writeMetrics writes per-topic metrics in the Prometheus text exposition
+// format, labelled by topic ID and name.
+func writeMetrics(w io.Writer, cfg *model.Config, data *ProgressData, now time.Time) error {
+	checkStreaks(data)
+	statuses := topicStatuses(cfg, data)
+	bw := bufio.NewWriter(w)
+
+	metric := func(name, kind, help string, value func(st topicStatus) float64) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+		for _, st := range statuses {
+			fmt.Fprintf(bw, "%s{topic=\"%s\",name=\"%s\"} %g\n", name, metricLabel(st.ID), metricLabel(st.Name), value(st))
+		}
+	}
+	boolValue := func(b bool) float64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+
+	longest := make(map[string]int)
+	for _, st := range statuses {
+		_, longest[st.ID] = historyStreaks(dailyCounts(data.Topics[st.ID]), now)
+	}
+
+	metric("att_topic_today_checkins", "gauge", "Check-ins recorded today.",
+		func(st topicStatus) float64 { return float64(st.Today) })
+	metric("att_topic_daily_goal", "gauge", "Daily check-in goal.",
+		func(st topicStatus) float64 { return float64(st.DailyGoal) })
+	metric("att_topic_goal_met", "gauge", "1 if today's goal has been met.",
+		func(st topicStatus) float64 { return boolValue(st.GoalMet) })
+	metric("att_topic_enabled", "gauge", "1 if the topic is enabled.",
+		func(st topicStatus) float64 { return boolValue(st.Enabled) })
+	metric("att_topic_streak_days", "gauge", "Current streak in days.",
+		func(st topicStatus) float64 { return float64(st.Streak) })
+	metric("att_topic_longest_streak_days", "gauge", "Longest streak in days.",
+		func(st topicStatus) float64 { return float64(longest[st.ID]) })
+	metric("att_topic_checkins_total", "counter", "All-time check-ins.",
+		func(st topicStatus) float64 { return float64(st.TotalCheckIns) })
+
+	fmt.Fprintf(bw, "# HELP att_topic_last_checkin_timestamp_seconds Time of the latest check-in.\n")
+	fmt.Fprintf(bw, "# TYPE att_topic_last_checkin_timestamp_seconds gauge\n")
+	for _, st := range statuses {
+		if t, err := time.Parse(time.RFC3339, st.LastCheckIn); err == nil {
+			fmt.Fprintf(bw, "att_topic_last_checkin_timestamp_seconds{topic=\"%s\",name=\"%s\"} %d\n",
+				metricLabel(st.ID), metricLabel(st.Name), t.Unix())
+		}
+	}
+	return bw.Flush()
+}
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func metricLabel(s string) string {
+	return metricLabelEscaper.Replace(s)
+}
@@ -6,10 +6,30 @@ type TopicConfig struct {
 	DailyGoal int    `json:"daily_goal"`
 	Emoji     string `json:"emoji"`
 	Enabled   bool   `json:"enabled"`
+	// Reminders are the HH:MM times at which `att remind daemon` nags
+	// about this topic while its daily goal is unmet.
+	Reminders []string `json:"reminders,omitempty"`
+}
+
+// Webhook is an HTTP endpoint that receives a JSON POST for each event.
+// An empty Events list subscribes to every event.
+type Webhook struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
 }
 
 type Config struct {
-	DataPath string                  `json:"data_path"`
-	SSHURL   string                  `json:"ssh_url,omitempty"`
-	Topics   map[string]*TopicConfig `json:"topics"`
+	// Version is the schema version of config.json; see schema.go.
+	Version  int               `json:"version"`
+	DataPath string            `json:"data_path"`
+	SSHURL   string            `json:"ssh_url,omitempty"`
+	Badges   bool              `json:"badges,omitempty"`
+	Webhooks []*Webhook        `json:"webhooks,omitempty"`
+	Hooks    map[string]string `json:"hooks,omitempty"`
+	// QuietHours is an HH:MM-HH:MM range in which no reminders are shown.
+	QuietHours string `json:"quiet_hours,omitempty"`
+	// Topics are kept in topics.json in the data repo rather than in
+	// config.json, so that they sync between machines.
+	Topics map[string]*TopicConfig `json:"-"`
 }
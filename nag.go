@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+func newNagCommand() *command {
+	nagCmd := newCommand("nag", "", "Warn about streaks that break at midnight; exits 6 if any do")
+	nagCmd.maxArgs = 0
+	unmet := nagCmd.flags.Bool("unmet", false, "also warn about topics below today's goal whose streak is safe")
+	mailTo := nagCmd.flags.String("mail", "", "email the warning to `address` with sendmail instead of printing it")
+	sendmail := nagCmd.flags.String("sendmail", "", "sendmail `command` to use (default: sendmail from PATH, /usr/sbin or /usr/lib)")
+	nagCmd.examples = []string{
+		"att nag",
+		"0 21 * * * att nag --mail me@example.com     # crontab",
+		"att nag --unmet || notify-send 'Check in!'",
+	}
+	nagCmd.run = func([]string) error { return nag(*unmet, *mailTo, *sendmail) }
+	return nagCmd
+}
+
+// nag prints, or mails, the enabled topics whose streak ends at midnight:
+// those with a streak going but no check-in today, since any check-in keeps
+// a streak alive. Nothing is printed when all is well, so cron only sends
+// mail when there is something to do.
+func nag(unmet bool, mailTo, sendmail string) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	checkStreaks(data)
+
+	var atRisk, below []topicStatus
+	for _, st := range topicStatuses(cfg, data) {
+		switch {
+		case !st.Enabled:
+		case st.Streak > 0 && st.Today == 0:
+			atRisk = append(atRisk, st)
+		case unmet && !st.GoalMet:
+			below = append(below, st)
+		}
+	}
+	if len(atRisk) == 0 && len(below) == 0 {
+		if isatty.IsTerminal(os.Stdout.Fd()) {
+			fmt.Println("✓ No streaks at risk today")
+		}
+		return nil
+	}
+
+	subject := fmt.Sprintf("att: %d of today's goals unmet", len(below))
+	if len(atRisk) > 0 {
+		subject = fmt.Sprintf("att: %d streaks break at midnight", len(atRisk))
+		if len(atRisk) == 1 {
+			subject = "att: 1 streak breaks at midnight"
+		}
+	}
+	var body bytes.Buffer
+	writeNag(&body, atRisk, below)
+
+	if mailTo == "" {
+		fmt.Printf("%s (%s)\n\n%s", subject, todayString(), body.String())
+	} else if err := sendMail(sendmail, mailTo, subject, body.String()); err != nil {
+		return err
+	}
+	return &cliError{code: exitAtRisk, err: errReported}
+}
+
+func writeNag(w io.Writer, atRisk, below []topicStatus) {
+	row := func(st topicStatus) {
+		fmt.Fprintf(w, "  %s %-20s %d/%d today", st.Emoji, st.Name, st.Today, st.DailyGoal)
+		if st.Streak > 0 {
+			fmt.Fprintf(w, "   %d-day streak", st.Streak)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(atRisk) > 0 {
+		fmt.Fprintln(w, "Streaks that break at midnight:")
+		for _, st := range atRisk {
+			row(st)
+		}
+		fmt.Fprintln(w)
+	}
+	if len(below) > 0 {
+		fmt.Fprintln(w, "Below today's goal:")
+		for _, st := range below {
+			row(st)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, `Check in with: att checkin <topic> "<remark>"`)
+}
+
+// sendMail hands a plain-text message to the local MTA, which expects
+// local line endings rather than CRLF.
+func sendMail(sendmail, to, subject, body string) error {
+	if sendmail == "" {
+		for _, candidate := range []string{"sendmail", "/usr/sbin/sendmail", "/usr/lib/sendmail"} {
+			if path, err := exec.LookPath(candidate); err == nil {
+				sendmail = path
+				break
+			}
+		}
+		if sendmail == "" {
+			return configErrorf("sendmail not found; install an MTA or pass --sendmail")
+		}
+	}
+	if strings.ContainsAny(to, "\r\n") {
+		return usageErrorf("invalid --mail address %q", to)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "To: %s\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\n")
+	fmt.Fprintf(&msg, "Content-Type: text/plain; charset=utf-8\n")
+	fmt.Fprintf(&msg, "Content-Transfer-Encoding: 8bit\n\n")
+	msg.WriteString(body)
+
+	cmd := exec.Command("sh", "-c", sendmail+" -t -i")
+	cmd.Stdin = &msg
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("sendmail: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"att/model"
+)
+
+// Output formats accepted by --format.
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatYAML = "yaml"
+	formatTSV  = "tsv"
+)
+
+// addFormatFlags registers --json and --format on cmd. The returned
+// function resolves the chosen format once flags have been parsed.
+func addFormatFlags(cmd *command) func() (string, error) {
+	asJSON := cmd.flags.Bool("json", false, "shorthand for --format=json")
+	format := cmd.flags.String("format", formatText, "output `format`: text, json, yaml or tsv")
+	return func() (string, error) {
+		if *asJSON {
+			return formatJSON, nil
+		}
+		switch *format {
+		case formatText, formatJSON, formatYAML, formatTSV:
+			return *format, nil
+		}
+		return "", usageErrorf("unknown format %q: choose text, json, yaml or tsv", *format)
+	}
+}
+
+// The types below are att's machine-readable output schemas, documented
+// in docs/output.md. Fields may be added but must never be renamed or
+// removed.
+
+type topicStatus struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Emoji         string `json:"emoji"`
+	Enabled       bool   `json:"enabled"`
+	DailyGoal     int    `json:"daily_goal"`
+	Today         int    `json:"today"`
+	GoalMet       bool   `json:"goal_met"`
+	Streak        int    `json:"streak"`
+	TotalCheckIns int    `json:"total_checkins"`
+	LastCheckIn   string `json:"last_checkin,omitempty"`
+}
+
+type statusReport struct {
+	Date   string        `json:"date"`
+	Topics []topicStatus `json:"topics"`
+}
+
+type configReport struct {
+	Profile    string        `json:"profile"`
+	ConfigFile string        `json:"config_file"`
+	DataPath   string        `json:"data_path"`
+	Remote     string        `json:"remote,omitempty"`
+	Badges     bool          `json:"badges"`
+	Topics     []topicStatus `json:"topics"`
+}
+
+type checkinReport struct {
+	Date   string      `json:"date"`
+	Remark string      `json:"remark"`
+	Topic  topicStatus `json:"topic"`
+}
+
+// newCheckinReport describes a check-in just recorded by recordCheckin.
+func newCheckinReport(topicID string, cfg *model.TopicConfig, topicData *TopicData, progress int, remark string) checkinReport {
+	return checkinReport{
+		Date:   topicData.LastDate,
+		Remark: remark,
+		Topic: topicStatus{
+			ID:            topicID,
+			Name:          cfg.Name,
+			Emoji:         cfg.Emoji,
+			Enabled:       cfg.Enabled,
+			DailyGoal:     cfg.DailyGoal,
+			Today:         progress,
+			GoalMet:       progress >= cfg.DailyGoal,
+			Streak:        topicData.Streak,
+			TotalCheckIns: topicData.TotalCheckIns,
+			LastCheckIn:   topicData.LastDate,
+		},
+	}
+}
+
+var topicStatusHeader = []string{"id", "name", "emoji", "enabled", "daily_goal", "today", "goal_met", "streak", "total_checkins", "last_checkin"}
+
+func (t topicStatus) tsvRow() []string {
+	return []string{t.ID, t.Name, t.Emoji, strconv.FormatBool(t.Enabled), strconv.Itoa(t.DailyGoal),
+		strconv.Itoa(t.Today), strconv.FormatBool(t.GoalMet), strconv.Itoa(t.Streak),
+		strconv.Itoa(t.TotalCheckIns), t.LastCheckIn}
+}
+
+func newTopicStatus(id string, cfg *model.TopicConfig, data *ProgressData) topicStatus {
+	status := topicStatus{
+		ID:        id,
+		Name:      cfg.Name,
+		Emoji:     cfg.Emoji,
+		Enabled:   cfg.Enabled,
+		DailyGoal: cfg.DailyGoal,
+	}
+	if data == nil {
+		return status
+	}
+	status.Today = getTodayProgress(data, id)
+	status.GoalMet = status.Today >= cfg.DailyGoal
+	if topicData := data.Topics[id]; topicData != nil {
+		status.Streak = topicData.Streak
+		status.TotalCheckIns = topicData.TotalCheckIns
+		status.LastCheckIn = topicData.LastDate
+	}
+	return status
+}
+
+func topicStatuses(cfg *model.Config, data *ProgressData) []topicStatus {
+	statuses := []topicStatus{}
+	for _, id := range sortedTopicIDs(cfg.Topics) {
+		statuses = append(statuses, newTopicStatus(id, cfg.Topics[id], data))
+	}
+	return statuses
+}
+
+func sortedTopicIDs(topics map[string]*model.TopicConfig) []string {
+	ids := make([]string, 0, len(topics))
+	for id := range topics {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func todayString() string {
+	return time.Now().Format("2006-01-02")
+}
+
+// writeStructured writes v as JSON or YAML, or rows as TSV with header
+// as the first line.
+func writeStructured(w io.Writer, format string, v any, header []string, rows [][]string) error {
+	switch format {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case formatYAML:
+		_, err := io.WriteString(w, strings.Join(yamlLines(reflect.ValueOf(v)), "\n")+"\n")
+		return err
+	case formatTSV:
+		return writeTSV(w, header, rows)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+func writeTSV(w io.Writer, header []string, rows [][]string) error {
+	clean := strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+	for _, row := range append([][]string{header}, rows...) {
+		fields := make([]string, len(row))
+		for i, f := range row {
+			fields[i] = clean.Replace(f)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(fields, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// yamlLines renders v as block-style YAML, honouring json struct tags so
+// both formats share one schema. Strings are always double-quoted.
+func yamlLines(v reflect.Value) []string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return []string{"null"}
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		var lines []string
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			if strings.Contains(opts, "omitempty") && v.Field(i).IsZero() {
+				continue
+			}
+			lines = append(lines, yamlEntry(name+":", v.Field(i))...)
+		}
+		if lines == nil {
+			return []string{"{}"}
+		}
+		return lines
+	case reflect.Map:
+		if v.Len() == 0 {
+			return []string{"{}"}
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		var lines []string
+		for _, k := range keys {
+			lines = append(lines, yamlEntry(yamlScalar(k)+":", v.MapIndex(k))...)
+		}
+		return lines
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return []string{"[]"}
+		}
+		var lines []string
+		for i := 0; i < v.Len(); i++ {
+			item := yamlLines(v.Index(i))
+			lines = append(lines, "- "+item[0])
+			for _, l := range item[1:] {
+				lines = append(lines, "  "+l)
+			}
+		}
+		return lines
+	}
+	return []string{yamlScalar(v)}
+}
+
+// yamlEntry renders "key: value", nesting collections on following lines.
+func yamlEntry(key string, v reflect.Value) []string {
+	child := yamlLines(v)
+	if isYAMLCollection(v) && child[0] != "[]" && child[0] != "{}" {
+		lines := []string{key}
+		for _, l := range child {
+			lines = append(lines, "  "+l)
+		}
+		return lines
+	}
+	return []string{key + " " + child[0]}
+}
+
+func isYAMLCollection(v reflect.Value) bool {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		return true
+	}
+	return false
+}
+
+func yamlScalar(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		// A JSON string is a valid YAML double-quoted scalar, as long as
+		// <, > and & are left alone rather than escaped for HTML.
+		var b strings.Builder
+		enc := json.NewEncoder(&b)
+		enc.SetEscapeHTML(false)
+		enc.Encode(v.String())
+		return strings.TrimSuffix(b.String(), "\n")
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprintf("%q", fmt.Sprint(v.Interface()))
+}
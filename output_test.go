@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestYAMLScalarQuoting(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		// Strings YAML would otherwise read as booleans, nulls or numbers.
+		{"yes", `"yes"`},
+		{"no", `"no"`},
+		{"on", `"on"`},
+		{"null", `"null"`},
+		{"~", `"~"`},
+		{"3", `"3"`},
+		{"1e3", `"1e3"`},
+		{"", `""`},
+		// Indicators that would start a mapping, comment, sequence or more.
+		{"a: b", `"a: b"`},
+		{"key:", `"key:"`},
+		{"# not a comment", `"# not a comment"`},
+		{"see #3", `"see #3"`},
+		{"-fix typo", `"-fix typo"`},
+		{"- item", `"- item"`},
+		{"[x]", `"[x]"`},
+		{"{x}", `"{x}"`},
+		{"&anchor", `"&anchor"`},
+		{"*alias", `"*alias"`},
+		{"!tag", `"!tag"`},
+		{"|", `"|"`},
+		{">", `">"`},
+		{"<b>&amp;", `"<b>&amp;"`},
+		{"'single'", `"'single'"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`back\slash`, `"back\\slash"`},
+		{" padded ", `" padded "`},
+		{"two\nlines", `"two\nlines"`},
+		{"tab\there", `"tab\there"`},
+		{"emoji 💻", `"emoji 💻"`},
+		// Non-strings stay plain.
+		{true, "true"},
+		{42, "42"},
+		{-7, "-7"},
+		{uint8(5), "5"},
+		{2.5, "2.5"},
+	}
+	for _, tt := range tests {
+		if got := yamlScalar(reflect.ValueOf(tt.in)); got != tt.want {
+			t.Errorf("yamlScalar(%#v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestYAMLLines(t *testing.T) {
+	type inner struct {
+		Name string `json:"name"`
+		Goal int    `json:"daily_goal"`
+	}
+	type outer struct {
+		ID       string           `json:"id"`
+		Skip     string           `json:"-"`
+		Optional string           `json:"optional,omitempty"`
+		Untagged bool             //
+		Topic    *inner           `json:"topic"`
+		Missing  *inner           `json:"missing"`
+		List     []inner          `json:"list"`
+		Empty    []string         `json:"empty"`
+		Counts   map[string]int   `json:"counts"`
+		NoCounts map[string]int   `json:"no_counts"`
+		Nested   map[string][]int `json:"nested"`
+	}
+	v := outer{
+		ID:       "yes",
+		Skip:     "hidden",
+		Untagged: true,
+		Topic:    &inner{Name: "a: b", Goal: 2},
+		List:     []inner{{Name: "-x", Goal: 1}, {Name: "# y", Goal: 3}},
+		Counts:   map[string]int{"z": 1, "a:b": 2},
+		Nested:   map[string][]int{"k": {1, 2}},
+	}
+	want := strings.Join([]string{
+		`id: "yes"`,
+		`Untagged: true`,
+		`topic:`,
+		`  name: "a: b"`,
+		`  daily_goal: 2`,
+		`missing: null`,
+		`list:`,
+		`  - name: "-x"`,
+		`    daily_goal: 1`,
+		`  - name: "# y"`,
+		`    daily_goal: 3`,
+		`empty: []`,
+		`counts:`,
+		`  "a:b": 2`,
+		`  "z": 1`,
+		`no_counts: {}`,
+		`nested:`,
+		`  "k":`,
+		`    - 1`,
+		`    - 2`,
+	}, "\n")
+	if got := strings.Join(yamlLines(reflect.ValueOf(v)), "\n"); got != want {
+		t.Errorf("yamlLines =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteStructuredYAMLTopLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{"empty list", []string{}, "[]\n"},
+		{"list of strings", []string{"yes", "-"}, "- \"yes\"\n- \"-\"\n"},
+		{"empty struct", struct{}{}, "{}\n"},
+		{"nil", (*struct{})(nil), "null\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeStructured(&buf, formatYAML, tt.in, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTSVCleansFields(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeTSV(&buf, []string{"date", "remark"}, [][]string{{"2025-01-31", "tab\there\nand\rnewline"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "date\tremark\n2025-01-31\ttab here and newline\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// att follows the XDG base directory spec: config lives in
+// $XDG_CONFIG_HOME/att (~/.config/att) and data in $XDG_DATA_HOME/att
+// (~/.local/share/att). ATT_CONFIG names a config file and ATT_DATA a data
+// directory, overriding both. Older versions kept everything in ~/.att,
+// and config is moved out of there the first time it is needed.
+
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, fallback)
+}
+
+// legacyHome is where att kept its config, and by default its data,
+// before it followed the XDG spec.
+func legacyHome() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".att")
+}
+
+var migrateOnce sync.Once
+
+// configDir returns att's config directory, moving legacy config there
+// first if needed.
+func configDir() string {
+	dir := filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), "att")
+	migrateOnce.Do(func() { migrateLegacyConfig(dir) })
+	return dir
+}
+
+// dataDir returns where new data repos go. An existing legacy ~/.att repo
+// is kept for the default profile, so re-running setup finds its history.
+func dataDir(profile string) string {
+	if profile == defaultProfile {
+		if _, err := os.Stat(filepath.Join(legacyHome(), "progress.json")); err == nil {
+			return legacyHome()
+		}
+		return filepath.Join(xdgDir("XDG_DATA_HOME", ".local/share"), "att")
+	}
+	return filepath.Join(xdgDir("XDG_DATA_HOME", ".local/share"), "att-"+profile)
+}
+
+// dataOverride returns the data directory set with ATT_DATA, if any.
+func dataOverride() string {
+	if dir := os.Getenv("ATT_DATA"); dir != "" {
+		return expandHome(dir)
+	}
+	return ""
+}
+
+// migrateLegacyConfig moves config.json, profiles and the active profile
+// from ~/.att (or ~/.att_config.json) into dir, leaving data repos where
+// they are since config records their paths. Anything already present in
+// dir wins; nothing is overwritten.
+func migrateLegacyConfig(dir string) {
+	home, _ := os.UserHomeDir()
+	moves := []struct{ from, to string }{
+		{filepath.Join(legacyHome(), "config.json"), filepath.Join(dir, "config.json")},
+		{filepath.Join(home, ".att_config.json"), filepath.Join(dir, "config.json")},
+		{filepath.Join(legacyHome(), "profiles"), filepath.Join(dir, "profiles")},
+		{filepath.Join(legacyHome(), "profile"), filepath.Join(dir, "profile")},
+	}
+	var moved []string
+	for _, m := range moves {
+		if _, err := os.Stat(m.to); err == nil {
+			continue
+		}
+		if _, err := os.Stat(m.from); err != nil {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not create %s: %v\n", dir, err)
+			return
+		}
+		if err := movePath(m.from, m.to); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not move %s to %s: %v\n", m.from, m.to, err)
+			continue
+		}
+		moved = append(moved, m.from)
+	}
+	if len(moved) == 0 {
+		return
+	}
+
+	// The legacy data repo's first commit included config.json. Tell Git
+	// to ignore its absence, or `git pull --rebase` would refuse to run.
+	// Committing the removal instead would delete the file on other
+	// machines that still use it.
+	legacyConfig := filepath.Join(legacyHome(), "config.json")
+	if moved[0] == legacyConfig && runGit(legacyHome(), "ls-files", "--error-unmatch", "config.json") == nil {
+		runGit(legacyHome(), "update-index", "--skip-worktree", "config.json")
+	}
+	fmt.Fprintf(os.Stderr, "Note: att now keeps its config in %s; moved %s there. Your data has not moved.\n",
+		dir, strings.Join(moved, ", "))
+}
+
+// movePath renames from to to, copying a regular file when they are on
+// different filesystems.
+func movePath(from, to string) error {
+	err := os.Rename(from, to)
+	if err == nil || !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("cannot move a directory across filesystems")
+	}
+	dst, err := os.OpenFile(to, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Remove(from)
+}
+
+// configFileOverridden reports whether the config file was named with
+// --config or ATT_CONFIG rather than chosen by profile.
+func configFileOverridden() bool {
+	return configOverride != "" || os.Getenv("ATT_CONFIG") != ""
+}
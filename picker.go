@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+
+	"att/model"
+	"att/ui"
+)
+
+const (
+	pickTopicStage = iota
+	remarkStage
+)
+
+// checkinPrompt is an inline TUI that lets the user fuzzy-pick a topic
+// and then type a remark.
+type checkinPrompt struct {
+	topics    map[string]*model.TopicConfig
+	query     string
+	matches   []string
+	cursor    int
+	stage     int
+	topicID   string
+	remark    string
+	done      bool
+	cancelled bool
+}
+
+func newCheckinPrompt(topics map[string]*model.TopicConfig, topicID string) *checkinPrompt {
+	p := &checkinPrompt{topics: topics}
+	if topicID != "" {
+		p.topicID = topicID
+		p.stage = remarkStage
+	}
+	p.matches = rankTopics(topics, "")
+	return p
+}
+
+func (p *checkinPrompt) Init() tea.Cmd {
+	return nil
+}
+
+func (p *checkinPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	key, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return p, nil
+	}
+
+	switch key.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		p.cancelled = true
+		return p, tea.Quit
+	}
+
+	if p.stage == pickTopicStage {
+		switch key.Type {
+		case tea.KeyUp, tea.KeyCtrlP:
+			if p.cursor > 0 {
+				p.cursor--
+			}
+		case tea.KeyDown, tea.KeyCtrlN, tea.KeyTab:
+			if p.cursor < len(p.matches)-1 {
+				p.cursor++
+			}
+		case tea.KeyEnter:
+			if len(p.matches) > 0 {
+				p.topicID = p.matches[p.cursor]
+				p.stage = remarkStage
+			}
+		case tea.KeyBackspace:
+			p.setQuery(trimLastRune(p.query))
+		case tea.KeyRunes, tea.KeySpace:
+			p.setQuery(p.query + string(key.Runes))
+		}
+		return p, nil
+	}
+
+	switch key.Type {
+	case tea.KeyEnter:
+		if strings.TrimSpace(p.remark) != "" {
+			p.done = true
+			return p, tea.Quit
+		}
+	case tea.KeyBackspace:
+		p.remark = trimLastRune(p.remark)
+	case tea.KeyRunes, tea.KeySpace:
+		p.remark += string(key.Runes)
+	}
+	return p, nil
+}
+
+func (p *checkinPrompt) setQuery(q string) {
+	p.query = q
+	p.matches = rankTopics(p.topics, q)
+	p.cursor = 0
+}
+
+func trimLastRune(s string) string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return s
+	}
+	return string(r[:len(r)-1])
+}
+
+func (p *checkinPrompt) View() string {
+	if p.done || p.cancelled {
+		return ""
+	}
+
+	promptStyle := lipgloss.NewStyle().Foreground(ui.PrimaryColor).Bold(true)
+	mutedStyle := lipgloss.NewStyle().Foreground(ui.MutedColor)
+	cursorStyle := lipgloss.NewStyle().Foreground(ui.PrimaryColor)
+
+	var b strings.Builder
+	if p.stage == remarkStage {
+		t := p.topics[p.topicID]
+		b.WriteString(ui.TopicStyle.MarginTop(0).Render(fmt.Sprintf("%s %s", t.Emoji, t.Name)))
+		b.WriteString("\n")
+		b.WriteString(promptStyle.Render("Remark: ") + p.remark + cursorStyle.Render("█"))
+		b.WriteString("\n")
+		b.WriteString(mutedStyle.Render("enter to save • esc to cancel"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString(promptStyle.Render("Topic: ") + p.query + cursorStyle.Render("█"))
+	b.WriteString("\n")
+	if len(p.matches) == 0 {
+		b.WriteString(mutedStyle.Render("  no matching topics"))
+		b.WriteString("\n")
+	}
+	for i, id := range p.matches {
+		t := p.topics[id]
+		line := fmt.Sprintf("%s %s (%s)", t.Emoji, t.Name, id)
+		if i == p.cursor {
+			b.WriteString(cursorStyle.Render("› ") + lipgloss.NewStyle().Bold(true).Render(line))
+		} else {
+			b.WriteString("  " + mutedStyle.Render(line))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(mutedStyle.Render("type to filter • ↑/↓ to move • enter to select • esc to cancel"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// promptCheckin asks for whatever checkin is missing: the topic (when
+// topicID is empty) and the remark. ok is false if the user cancelled.
+func promptCheckin(topics map[string]*model.TopicConfig, topicID string) (id, remark string, ok bool, err error) {
+	if !isInteractive() {
+		return "", "", false, usageErrorf("missing topic or remark\nUsage: att checkin <topic> <remark>")
+	}
+
+	p := newCheckinPrompt(topics, topicID)
+	if _, err := tea.NewProgram(p).Run(); err != nil {
+		return "", "", false, err
+	}
+	if p.cancelled {
+		return "", "", false, nil
+	}
+	return p.topicID, strings.TrimSpace(p.remark), true, nil
+}
+
+func isInteractive() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+}
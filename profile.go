@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"att/model"
+)
+
+// Profiles keep separate sets of topics, each with its own config file and
+// data repo. The default profile uses config.json in the config directory;
+// profile NAME uses profiles/NAME.json there and, unless configured
+// otherwise, a data repo named att-NAME next to the default one.
+const defaultProfile = "default"
+
+// profileOverride is set by the global --profile flag.
+var profileOverride string
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// currentProfileFile remembers the profile chosen with `att profile switch`.
+func currentProfileFile() string {
+	return filepath.Join(configDir(), "profile")
+}
+
+// currentProfile returns the active profile: --profile, then $ATT_PROFILE,
+// then the one chosen with `att profile switch`.
+func currentProfile() string {
+	if profileOverride != "" {
+		return profileOverride
+	}
+	if env := os.Getenv("ATT_PROFILE"); env != "" {
+		return env
+	}
+	if content, err := os.ReadFile(currentProfileFile()); err == nil {
+		if name := strings.TrimSpace(string(content)); name != "" {
+			return name
+		}
+	}
+	return defaultProfile
+}
+
+func profileConfigPath(name string) string {
+	if name == defaultProfile {
+		return filepath.Join(configDir(), "config.json")
+	}
+	return filepath.Join(configDir(), "profiles", name+".json")
+}
+
+// profileExists reports whether name has a config file. The default
+// profile always exists, even before setup.
+func profileExists(name string) bool {
+	if name == defaultProfile {
+		return true
+	}
+	_, err := os.Stat(profileConfigPath(name))
+	return err == nil
+}
+
+// attArgs returns the global flags that make another att process, such as
+// a hook or background job, use the same config as this one.
+func attArgs() []string {
+	if configFileOverridden() {
+		abs, err := filepath.Abs(getConfigPath())
+		if err != nil {
+			abs = getConfigPath()
+		}
+		return []string{"--config", abs}
+	}
+	return []string{"--profile", currentProfile()}
+}
+
+func newProfileCommand() *command {
+	profileCmd := newCommand("profile", "", "Manage profiles with separate topics and data")
+
+	listCmd := newCommand("list", "", "List profiles")
+	listCmd.aliases = []string{"ls"}
+	listCmd.maxArgs = 0
+	listCmd.run = func([]string) error { return profileList() }
+
+	createCmd := newCommand("create", "<name>", "Create a profile")
+	createCmd.minArgs, createCmd.maxArgs = 1, 1
+	dataPath := createCmd.flags.String("data-path", "", "data directory `path` (default: att-<name> in $XDG_DATA_HOME)")
+	remote := createCmd.flags.String("remote", "", "Git remote `url` for the profile's data repo")
+	createCmd.examples = []string{
+		"att profile create work",
+		"att profile create work --remote git@github.com:me/att-work.git",
+		"att --profile work topic add standup 'Daily standup' 1 🗣️",
+	}
+	createCmd.run = func(args []string) error { return profileCreate(args[0], *dataPath, *remote) }
+
+	switchCmd := newCommand("switch", "<name>", "Make a profile the default for this user")
+	switchCmd.aliases = []string{"use"}
+	switchCmd.minArgs, switchCmd.maxArgs = 1, 1
+	switchCmd.examples = []string{
+		"att profile switch work",
+		"att profile switch default",
+	}
+	switchCmd.run = func(args []string) error { return profileSwitch(args[0]) }
+
+	profileCmd.add(listCmd, createCmd, switchCmd)
+	return profileCmd
+}
+
+func profileNames() ([]string, error) {
+	names := []string{defaultProfile}
+	entries, err := os.ReadDir(filepath.Join(configDir(), "profiles"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, configErrorf("reading profiles: %v", err)
+	}
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() && profileNamePattern.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names, nil
+}
+
+func profileList() error {
+	names, err := profileNames()
+	if err != nil {
+		return err
+	}
+	current := currentProfile()
+	for _, name := range names {
+		marker := " "
+		if name == current {
+			marker = "*"
+		}
+		detail := "not set up"
+		if content, err := os.ReadFile(profileConfigPath(name)); err == nil {
+			// Profiles not used since topics moved to the data repo still
+			// list them in their config file.
+			var cfg struct {
+				model.Config
+				Topics map[string]any `json:"topics"`
+			}
+			if json.Unmarshal(content, &cfg) == nil {
+				topics, _ := readTopics(cfg.DataPath)
+				n := len(topics)
+				if topics == nil {
+					n = len(cfg.Topics)
+				}
+				detail = fmt.Sprintf("%d topics, %s", n, cfg.DataPath)
+			} else {
+				detail = "invalid config"
+			}
+		}
+		fmt.Printf("%s %-15s %s\n", marker, name, detail)
+	}
+	if !profileExists(current) {
+		fmt.Printf("\nThe active profile '%s' does not exist; create it with: att profile create %s\n", current, current)
+	}
+	return nil
+}
+
+func profileCreate(name, dataPath, remote string) error {
+	if !profileNamePattern.MatchString(name) {
+		return usageErrorf("invalid profile name %q: use lowercase letters, digits, - and _", name)
+	}
+	if name == defaultProfile {
+		return usageErrorf("the default profile always exists; set it up with: att setup")
+	}
+	if profileExists(name) {
+		return usageErrorf("profile '%s' already exists", name)
+	}
+	if dataPath == "" {
+		dataPath = dataDir(name)
+	}
+	cfg := &model.Config{
+		DataPath: expandHome(dataPath),
+		SSHURL:   remote,
+		Topics:   make(map[string]*model.TopicConfig),
+	}
+
+	// Written straight to the profile's file: saveConfig would honour
+	// --config and ATT_CONFIG and overwrite another config.
+	if err := saveTopics(cfg); err != nil {
+		return err
+	}
+	if err := writeConfigFile(profileConfigPath(name), cfg); err != nil {
+		return err
+	}
+	if err := initRepo(cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Created profile '%s'\n", name)
+	fmt.Printf("  Config: %s\n", profileConfigPath(name))
+	fmt.Printf("  Data:   %s\n", cfg.DataPath)
+	fmt.Println()
+	fmt.Printf("Add topics with: att --profile %s topic add <id> <name> <goal> [emoji]\n", name)
+	fmt.Printf("Use it by default with: att profile switch %s\n", name)
+	return nil
+}
+
+func profileSwitch(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return usageErrorf("invalid profile name %q: use lowercase letters, digits, - and _", name)
+	}
+	if !profileExists(name) {
+		return notFoundErrorf("profile '%s' does not exist; create it with: att profile create %s", name, name)
+	}
+	if name == defaultProfile {
+		if err := os.Remove(currentProfileFile()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return configErrorf("switching profile: %v", err)
+		}
+	} else {
+		if err := os.MkdirAll(configDir(), 0755); err != nil {
+			return configErrorf("switching profile: %v", err)
+		}
+		if err := os.WriteFile(currentProfileFile(), []byte(name+"\n"), 0644); err != nil {
+			return configErrorf("switching profile: %v", err)
+		}
+	}
+	fmt.Printf("✓ Switched to profile '%s'\n", name)
+	if env := os.Getenv("ATT_PROFILE"); env != "" && env != name {
+		fmt.Printf("  Note: ATT_PROFILE=%s still takes precedence in this shell\n", env)
+	}
+	return nil
+}
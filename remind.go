@@ -0,0 +1,525 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"att/model"
+)
+
+func newRemindCommand() *command {
+	remindCmd := newCommand("remind", "", "Desktop reminders for unmet daily goals")
+
+	listCmd := newCommand("list", "", "Show reminder times, quiet hours and snoozes")
+	listCmd.aliases = []string{"ls"}
+	listCmd.maxArgs = 0
+	listCmd.run = func([]string) error { return remindList() }
+
+	setCmd := newCommand("set", "<topic> <HH:MM>...", "Set the times to remind about a topic")
+	setCmd.minArgs = 2
+	setCmd.topicArg = anyTopicArg
+	setCmd.examples = []string{
+		"att remind set dsa 12:00 20:00",
+	}
+	setCmd.run = func(args []string) error { return remindSet(args[0], args[1:]) }
+
+	clearCmd := newCommand("clear", "<topic>", "Stop reminding about a topic")
+	clearCmd.minArgs, clearCmd.maxArgs = 1, 1
+	clearCmd.topicArg = anyTopicArg
+	clearCmd.run = func(args []string) error { return remindSet(args[0], nil) }
+
+	quietCmd := newCommand("quiet", "<HH:MM-HH:MM|off>", "Set hours in which no reminders are shown")
+	quietCmd.minArgs, quietCmd.maxArgs = 1, 1
+	quietCmd.examples = []string{
+		"att remind quiet 22:00-07:00",
+		"att remind quiet off",
+	}
+	quietCmd.run = func(args []string) error { return remindQuiet(args[0]) }
+
+	snoozeCmd := newCommand("snooze", "[topic]", "Hold off reminders for a while, then remind again")
+	snoozeCmd.maxArgs = 1
+	snoozeCmd.topicArg = enabledTopicArg
+	snoozeFor := snoozeCmd.flags.Duration("for", time.Hour, "how long to snooze")
+	snoozeCmd.examples = []string{
+		"att remind snooze dsa",
+		"att remind snooze --for 30m     # every topic",
+	}
+	snoozeCmd.run = func(args []string) error {
+		query := ""
+		if len(args) == 1 {
+			query = args[0]
+		}
+		return remindSnooze(query, *snoozeFor)
+	}
+
+	daemonCmd := newCommand("daemon", "", "Send reminders until stopped")
+	daemonCmd.maxArgs = 0
+	once := daemonCmd.flags.Bool("once", false, "check once and exit, e.g. from cron")
+	daemonCmd.run = func([]string) error { return remindDaemon(*once) }
+
+	systemdCmd := newCommand("systemd", "", "Print a systemd user unit that runs the daemon")
+	systemdCmd.maxArgs = 0
+	install := systemdCmd.flags.Bool("install", false, "write the unit to ~/.config/systemd/user instead of printing it")
+	systemdCmd.examples = []string{
+		"att remind systemd --install && systemctl --user enable --now att-remind.service",
+	}
+	systemdCmd.run = func([]string) error { return remindSystemd(*install) }
+
+	remindCmd.add(listCmd, setCmd, clearCmd, quietCmd, snoozeCmd, daemonCmd, systemdCmd)
+	return remindCmd
+}
+
+// remindState is kept per machine in the data repo's .git directory, so
+// snoozing on one computer does not silence another.
+type remindState struct {
+	// Sent maps a topic ID to the last reminder slot shown for it, as
+	// "2006-01-02 15:04".
+	Sent map[string]string `json:"sent"`
+	// Snoozed maps a topic ID, or "*" for every topic, to when the snooze
+	// ends.
+	Snoozed map[string]time.Time `json:"snoozed"`
+}
+
+const snoozeAll = "*"
+
+func remindStatePath(dataPath string) string {
+	return filepath.Join(dataPath, ".git", "att-remind.json")
+}
+
+// updateRemindState applies fn to the reminder state under the data lock.
+func updateRemindState(dataPath string, fn func(*remindState)) error {
+	unlock, err := lockData(dataPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st := remindState{Sent: map[string]string{}, Snoozed: map[string]time.Time{}}
+	path := remindStatePath(dataPath)
+	content, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(content, &st); err != nil {
+			return dataErrorf("reading %s: %v", path, err)
+		}
+		if st.Sent == nil {
+			st.Sent = map[string]string{}
+		}
+		if st.Snoozed == nil {
+			st.Snoozed = map[string]time.Time{}
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return dataErrorf("reading %s: %v", path, err)
+	}
+
+	fn(&st)
+	content, err = json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return dataErrorf("writing %s: %v", path, err)
+	}
+	return nil
+}
+
+// parseClock parses an HH:MM time and returns it normalised.
+func parseClock(s string) (string, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return "", usageErrorf("invalid time %q, want HH:MM", s)
+	}
+	return t.Format("15:04"), nil
+}
+
+// inQuietHours reports whether now falls in a "22:00-07:00" style range,
+// which may wrap past midnight.
+func inQuietHours(quiet string, now time.Time) bool {
+	start, end, ok := strings.Cut(quiet, "-")
+	if !ok {
+		return false
+	}
+	clock := now.Format("15:04")
+	if start <= end {
+		return clock >= start && clock < end
+	}
+	return clock >= start || clock < end
+}
+
+func remindSet(query string, times []string) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	topicID, err := resolveTopic(cfg.Topics, query)
+	if err != nil {
+		return err
+	}
+	var slots []string
+	for _, t := range times {
+		slot, err := parseClock(t)
+		if err != nil {
+			return err
+		}
+		slots = append(slots, slot)
+	}
+	slices.Sort(slots)
+	slots = slices.Compact(slots)
+
+	var topicCfg *model.TopicConfig
+	err = updateTopics(cfg, "Reminders: "+topicID, func(topics map[string]*model.TopicConfig) error {
+		if topicCfg = topics[topicID]; topicCfg == nil {
+			return notFoundErrorf("topic '%s' was removed on another machine", topicID)
+		}
+		topicCfg.Reminders = slots
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(slots) == 0 {
+		fmt.Printf("✓ No more reminders for %s %s\n", topicCfg.Emoji, topicCfg.Name)
+		return nil
+	}
+	fmt.Printf("✓ Reminding about %s %s at %s while the goal is unmet\n", topicCfg.Emoji, topicCfg.Name, strings.Join(slots, ", "))
+	if !topicCfg.Enabled {
+		fmt.Printf("  The topic is disabled; enable it with: att topic enable %s\n", topicID)
+	}
+	return nil
+}
+
+func remindQuiet(value string) error {
+	quiet := ""
+	if value != "off" {
+		start, end, ok := strings.Cut(value, "-")
+		if !ok {
+			return usageErrorf("invalid quiet hours %q, want HH:MM-HH:MM or off", value)
+		}
+		var err error
+		if start, err = parseClock(start); err != nil {
+			return err
+		}
+		if end, err = parseClock(end); err != nil {
+			return err
+		}
+		quiet = start + "-" + end
+	}
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	cfg.QuietHours = quiet
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	if quiet == "" {
+		fmt.Println("✓ Quiet hours turned off")
+	} else {
+		fmt.Printf("✓ No reminders between %s\n", strings.Replace(quiet, "-", " and ", 1))
+	}
+	return nil
+}
+
+func remindSnooze(query string, d time.Duration) error {
+	if d <= 0 {
+		return usageErrorf("--for must be positive")
+	}
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	key, label := snoozeAll, "all reminders"
+	if query != "" {
+		if key, err = resolveTopic(cfg.Topics, query); err != nil {
+			return err
+		}
+		label = "reminders for " + cfg.Topics[key].Name
+	}
+	if err := initRepo(cfg); err != nil {
+		return err
+	}
+	until := time.Now().Add(d)
+	if err := updateRemindState(cfg.DataPath, func(st *remindState) { st.Snoozed[key] = until }); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Snoozed %s until %s\n", label, until.Format("15:04"))
+	return nil
+}
+
+func remindList() error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	checkStreaks(data)
+
+	quiet := cfg.QuietHours
+	if quiet == "" {
+		quiet = "off"
+	}
+	fmt.Printf("Quiet hours: %s\n\n", quiet)
+	for _, id := range sortedTopicIDs(cfg.Topics) {
+		tc := cfg.Topics[id]
+		times := "-"
+		if len(tc.Reminders) > 0 {
+			times = strings.Join(tc.Reminders, ", ")
+		}
+		note := ""
+		if !tc.Enabled {
+			note = "  (disabled)"
+		}
+		st := newTopicStatus(id, tc, data)
+		fmt.Printf("  %-15s %-20s %d/%d today%s\n", id, times, st.Today, st.DailyGoal, note)
+	}
+
+	content, err := os.ReadFile(remindStatePath(cfg.DataPath))
+	if err != nil {
+		return nil
+	}
+	var st remindState
+	if json.Unmarshal(content, &st) != nil {
+		return nil
+	}
+	now := time.Now()
+	for key, until := range st.Snoozed {
+		if until.After(now) {
+			if key == snoozeAll {
+				key = "all topics"
+			}
+			fmt.Printf("\nSnoozed: %s until %s\n", key, until.Format("15:04"))
+		}
+	}
+	return nil
+}
+
+// reminder is a notification the daemon has decided to show.
+type reminder struct {
+	topicID string
+	status  topicStatus
+}
+
+// dueReminders decides which topics to remind about now and records that
+// in the state. A topic is due when its latest reminder time today has
+// passed and was not shown yet, or when a snooze covering it just ended;
+// either way only while its goal is unmet. Reminders that fall in quiet
+// hours are held back until the quiet hours end, if that is still today.
+func dueReminders(cfg *model.Config, data *ProgressData, st *remindState, now time.Time) []reminder {
+	today := now.Format("2006-01-02")
+	clock := now.Format("15:04")
+	quiet := inQuietHours(cfg.QuietHours, now)
+
+	ended := make(map[string]bool)
+	for key, until := range st.Snoozed {
+		if until.After(now) {
+			continue
+		}
+		if quiet && until.Format("2006-01-02") == today {
+			continue
+		}
+		delete(st.Snoozed, key)
+		if until.Format("2006-01-02") == today {
+			ended[key] = true
+		}
+	}
+	snoozed := func(topicID string) bool {
+		for _, key := range []string{topicID, snoozeAll} {
+			if until, ok := st.Snoozed[key]; ok && until.After(now) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var due []reminder
+	for _, id := range sortedTopicIDs(cfg.Topics) {
+		tc := cfg.Topics[id]
+		if !tc.Enabled || len(tc.Reminders) == 0 {
+			continue
+		}
+		latest := ""
+		for _, slot := range tc.Reminders {
+			if slot <= clock {
+				latest = slot
+			}
+		}
+		if latest == "" {
+			continue
+		}
+		slot := today + " " + latest
+		fresh := st.Sent[id] < slot
+		if !fresh && !ended[id] && !ended[snoozeAll] {
+			continue
+		}
+		status := newTopicStatus(id, tc, data)
+		if status.GoalMet {
+			st.Sent[id] = slot
+			continue
+		}
+		if quiet || snoozed(id) {
+			continue
+		}
+		st.Sent[id] = slot
+		due = append(due, reminder{topicID: id, status: status})
+	}
+	return due
+}
+
+func remindDaemon(once bool) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	if err := initRepo(cfg); err != nil {
+		return err
+	}
+	if _, err := notifier(); err != nil {
+		return err
+	}
+	if !once {
+		fmt.Println("att reminder daemon started (Ctrl+C to stop)")
+	}
+	for {
+		if err := remindCheck(); err != nil {
+			if once {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		if once {
+			return nil
+		}
+		// Wake just after each minute starts, so a reminder set for 20:00
+		// shows at 20:00.
+		now := time.Now()
+		time.Sleep(now.Truncate(time.Minute).Add(time.Minute + time.Second).Sub(now))
+	}
+}
+
+// remindCheck shows the reminders due now. It re-reads the config and data
+// every time, so the daemon picks up changes without a restart.
+func remindCheck() error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	checkStreaks(data)
+
+	var due []reminder
+	now := time.Now()
+	err = updateRemindState(cfg.DataPath, func(st *remindState) {
+		due = dueReminders(cfg, data, st, now)
+	})
+	if err != nil {
+		return err
+	}
+	for _, r := range due {
+		summary, body := reminderText(r)
+		if err := notify(summary, body); err != nil {
+			return err
+		}
+		fmt.Printf("%s reminded about %s (%d/%d)\n", now.Format("2006-01-02 15:04"), r.topicID, r.status.Today, r.status.DailyGoal)
+	}
+	return nil
+}
+
+func reminderText(r reminder) (summary, body string) {
+	st := r.status
+	summary = fmt.Sprintf("%s %s: %d/%d today", st.Emoji, st.Name, st.Today, st.DailyGoal)
+	if st.Streak > 0 {
+		body = fmt.Sprintf("Your %d-day streak ends at midnight.\n", st.Streak)
+	}
+	body += fmt.Sprintf("Check in: att checkin %s\nSnooze: att remind snooze %s", r.topicID, r.topicID)
+	return summary, body
+}
+
+// notifier returns the command used to show notifications: notify-send
+// from libnotify, or gdbus calling the freedesktop notification service
+// directly.
+func notifier() (string, error) {
+	for _, name := range []string{"notify-send", "gdbus"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", configErrorf("no way to show notifications: install notify-send (libnotify) or gdbus (glib)")
+}
+
+func notify(summary, body string) error {
+	path, err := notifier()
+	if err != nil {
+		return err
+	}
+	var cmd *exec.Cmd
+	if filepath.Base(path) == "notify-send" {
+		cmd = exec.Command(path, "--app-name=att", summary, body)
+	} else {
+		// gdbus parses each argument as a GVariant, so strings are quoted.
+		cmd = exec.Command(path, "call", "--session",
+			"--dest", "org.freedesktop.Notifications",
+			"--object-path", "/org/freedesktop/Notifications",
+			"--method", "org.freedesktop.Notifications.Notify",
+			`"att"`, "0", `""`, strconv.Quote(summary), strconv.Quote(body), "[]", "{}", "-1")
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("showing notification: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func remindSystemd(install bool) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	environment := ""
+	if dir := dataOverride(); dir != "" {
+		environment = fmt.Sprintf("Environment=%s\n", strconv.Quote("ATT_DATA="+dir))
+	}
+	execStart := strconv.Quote(exe)
+	for _, arg := range attArgs() {
+		execStart += " " + strconv.Quote(arg)
+	}
+	unit := fmt.Sprintf(`[Unit]
+Description=att reminders for unmet daily goals
+After=graphical-session.target
+
+[Service]
+%sExecStart=%s remind daemon
+Restart=on-failure
+RestartSec=30
+
+[Install]
+WantedBy=default.target
+`, environment, execStart)
+
+	if !install {
+		fmt.Print(unit)
+		return nil
+	}
+	dir := filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), "systemd", "user")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return configErrorf("creating %s: %v", dir, err)
+	}
+	path := filepath.Join(dir, "att-remind.service")
+	if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+		return configErrorf("writing %s: %v", path, err)
+	}
+	fmt.Printf("✓ Wrote %s\n", path)
+	fmt.Println("  Start it with: systemctl --user daemon-reload && systemctl --user enable --now att-remind.service")
+	return nil
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"att/model"
+)
+
+func newReportCommand() *command {
+	reportCmd := newCommand("report", "", "Generate a weekly or monthly review in Markdown")
+	reportCmd.maxArgs = 0
+	week := reportCmd.flags.Bool("week", false, "report on this week (the default)")
+	month := reportCmd.flags.Bool("month", false, "report on this month")
+	previous := reportCmd.flags.Bool("previous", false, "report on the previous complete week or month instead")
+	out := reportCmd.flags.String("out", "", "write the report to `file` instead of stdout")
+	reportCmd.examples = []string{
+		"att report --week",
+		"att report --month --previous --out 2025-01.md",
+	}
+	reportCmd.run = func([]string) error {
+		if *week && *month {
+			return usageErrorf("choose either --week or --month")
+		}
+		kind := "week"
+		if *month {
+			kind = "month"
+		}
+
+		now := time.Now()
+		p, err := parsePeriod(kind, now)
+		if err != nil {
+			return err
+		}
+		if *previous {
+			p = statsPeriod{
+				start: p.prevStart,
+				days:  int(p.start.Sub(p.prevStart).Hours()/24 + 0.5),
+			}
+		} else if kind == "week" {
+			p.days = 7
+		} else {
+			p.days = p.start.AddDate(0, 1, -1).Day()
+		}
+
+		cfg, err := requireConfig()
+		if err != nil {
+			return err
+		}
+		data, err := readData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+
+		w := io.Writer(os.Stdout)
+		if *out != "" {
+			f, err := os.Create(expandHome(*out))
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		writeMarkdownReport(w, cfg, data, kind, p, now)
+		if *out != "" {
+			fmt.Fprintf(os.Stderr, "✓ Report written to %s\n", *out)
+		}
+		return nil
+	}
+	return reportCmd
+}
+
+// streakAsOf returns the current streak as it stood at the end of day.
+func streakAsOf(counts map[string]int, day time.Time) int {
+	upTo := make(map[string]int)
+	limit := dayKey(day)
+	for key, n := range counts {
+		if key <= limit {
+			upTo[key] = n
+		}
+	}
+	current, _ := historyStreaks(upTo, day)
+	return current
+}
+
+func writeMarkdownReport(w io.Writer, cfg *model.Config, data *ProgressData, kind string, p statsPeriod, now time.Time) {
+	start := p.start
+	end := start.AddDate(0, 0, p.days) // exclusive
+	lastDay := end.AddDate(0, 0, -1)
+	// Days after today have not happened yet and are not "missed".
+	elapsed := p.days
+	if today := startOfDay(now); today.Before(lastDay) {
+		elapsed = int(today.Sub(start).Hours()/24+0.5) + 1
+	}
+
+	entries := collectCheckIns(cfg, data, checkInFilter{since: start, until: end})
+
+	// Report on enabled topics and on any topic that saw activity.
+	active := make(map[string]bool)
+	for _, e := range entries {
+		active[e.TopicID] = true
+	}
+	var ids []string
+	for _, id := range sortedTopicIDs(cfg.Topics) {
+		if cfg.Topics[id].Enabled || active[id] {
+			ids = append(ids, id)
+		}
+	}
+
+	title := fmt.Sprintf("Week of %s", start.Format("2 January 2006"))
+	if kind == "month" {
+		title = start.Format("January 2006")
+	}
+	fmt.Fprintf(w, "# Progress report: %s\n\n", title)
+	fmt.Fprintf(w, "_%s → %s · generated %s_\n\n", dayKey(start), dayKey(lastDay), now.Format("2006-01-02 15:04"))
+
+	if len(ids) == 0 {
+		fmt.Fprintln(w, "No topics configured.")
+		return
+	}
+
+	counts := make(map[string]map[string]int)
+	for _, id := range ids {
+		counts[id] = dailyCounts(data.Topics[id])
+	}
+
+	fmt.Fprintln(w, "## Goal attainment")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Topic | Goal/day | Check-ins | Days goal met | Completion | Streak (start → end) |")
+	fmt.Fprintln(w, "| --- | ---: | ---: | ---: | ---: | --- |")
+	type consistency struct {
+		id       string
+		goalDays int
+	}
+	var ranking []consistency
+	for _, id := range ids {
+		tc := cfg.Topics[id]
+		ps := computePeriodStats([]string{id}, cfg, counts, start, elapsed)
+		before := streakAsOf(counts[id], start.AddDate(0, 0, -1))
+		after := streakAsOf(counts[id], start.AddDate(0, 0, elapsed-1))
+		fmt.Fprintf(w, "| %s %s | %d | %d | %d/%d | %.0f%% | %d → %d |\n",
+			tc.Emoji, markdownCell(tc.Name), tc.DailyGoal, ps.CheckIns, ps.GoalDays, elapsed,
+			ps.CompletionRate*100, before, after)
+		ranking = append(ranking, consistency{id, ps.GoalDays})
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "## Highlights")
+	fmt.Fprintln(w)
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "- No check-ins this period.")
+	} else {
+		perDay := make(map[string]int)
+		for _, e := range entries {
+			perDay[dayKey(e.time)]++
+		}
+		bestDay, bestCount := "", 0
+		for day, n := range perDay {
+			if n > bestCount || n == bestCount && day < bestDay {
+				bestDay, bestCount = day, n
+			}
+		}
+		best, _ := time.ParseInLocation("2006-01-02", bestDay, time.Local)
+		fmt.Fprintf(w, "- **Best day:** %s with %d check-ins\n", best.Format("Monday 2 January"), bestCount)
+		fmt.Fprintf(w, "- **Active days:** %d of %d\n", len(perDay), elapsed)
+
+		sort.SliceStable(ranking, func(i, j int) bool { return ranking[i].goalDays > ranking[j].goalDays })
+		if top := ranking[0]; top.goalDays > 0 {
+			tc := cfg.Topics[top.id]
+			fmt.Fprintf(w, "- **Most consistent:** %s %s (goal met %d/%d days)\n", tc.Emoji, tc.Name, top.goalDays, elapsed)
+		}
+	}
+
+	var missedLines []string
+	for _, id := range ids {
+		tc := cfg.Topics[id]
+		if !tc.Enabled {
+			continue
+		}
+		var missed []string
+		for d := 0; d < elapsed; d++ {
+			day := start.AddDate(0, 0, d)
+			if counts[id][dayKey(day)] < tc.DailyGoal {
+				missed = append(missed, day.Format("Mon 2 Jan"))
+			}
+		}
+		if len(missed) > 0 {
+			missedLines = append(missedLines, fmt.Sprintf("  - %s %s: %s", tc.Emoji, tc.Name, strings.Join(missed, ", ")))
+		}
+	}
+	if len(missedLines) > 0 {
+		fmt.Fprintln(w, "- **Goal missed:**")
+		for _, l := range missedLines {
+			fmt.Fprintln(w, l)
+		}
+	} else if len(entries) > 0 {
+		fmt.Fprintln(w, "- **Every goal met on every day** 🎉")
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "## Remarks")
+	for _, id := range ids {
+		var topicEntries []checkInEntry
+		for _, e := range entries {
+			if e.TopicID == id {
+				topicEntries = append(topicEntries, e)
+			}
+		}
+		if len(topicEntries) == 0 {
+			continue
+		}
+		// Entries are newest first; a review reads better chronologically.
+		sort.SliceStable(topicEntries, func(i, j int) bool { return topicEntries[i].time.Before(topicEntries[j].time) })
+
+		tc := cfg.Topics[id]
+		fmt.Fprintf(w, "\n### %s %s\n", tc.Emoji, tc.Name)
+		currentDay := ""
+		for _, e := range topicEntries {
+			local := e.time.Local()
+			if key := dayKey(local); key != currentDay {
+				fmt.Fprintf(w, "\n**%s**\n\n", local.Format("Monday 2 January"))
+				currentDay = key
+			}
+			fmt.Fprintf(w, "- %s %s\n", local.Format("15:04"), strings.ReplaceAll(e.Remark, "\n", " "))
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "_No remarks this period._")
+	}
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Schema versions of config.json and progress.json. Bump one when the
+// file's format changes, and register a migration from the previous
+// version below so that older files keep loading.
+const (
+	configSchemaVersion = 2
+	dataSchemaVersion   = 1
+)
+
+// migration upgrades a decoded file from schema version from to from+1.
+type migration struct {
+	from        int
+	description string
+	apply       func(doc map[string]any) error
+}
+
+var configMigrations = []migration{
+	{0, "add a schema version", func(map[string]any) error { return nil }},
+	{1, "move topics into the data repo", moveTopicsToDataRepo},
+}
+
+var dataMigrations = []migration{
+	{0, "add a schema version", func(map[string]any) error { return nil }},
+}
+
+// schemaFile describes a versioned file for upgradeSchema.
+type schemaFile struct {
+	name       string // shown in messages, e.g. "config.json"
+	path       string
+	current    int
+	migrations []migration
+	errorf     func(format string, a ...any) error
+}
+
+// upgradeSchema returns raw migrated to f.current, and the version it was
+// at. Files without a version field are version 0. A file from a newer
+// att is an error rather than being misread.
+func upgradeSchema(f schemaFile, raw []byte) ([]byte, int, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, 0, f.errorf("parsing %s: %v", f.path, err)
+	}
+	fileVersion := 0
+	if v, ok := doc["version"]; ok {
+		n, ok := v.(float64)
+		if !ok || n < 0 || n != float64(int(n)) {
+			return nil, 0, f.errorf("%s has an invalid version %v", f.path, v)
+		}
+		fileVersion = int(n)
+	}
+	if fileVersion > f.current {
+		return nil, fileVersion, f.errorf("%s uses schema version %d, but att %s only understands up to %d; upgrade att to use it",
+			f.path, fileVersion, version, f.current)
+	}
+	if fileVersion == f.current {
+		return raw, fileVersion, nil
+	}
+
+	for v := fileVersion; v < f.current; v++ {
+		var step *migration
+		for i := range f.migrations {
+			if f.migrations[i].from == v {
+				step = &f.migrations[i]
+			}
+		}
+		if step == nil {
+			return nil, fileVersion, f.errorf("no migration for %s from schema version %d", f.name, v)
+		}
+		if err := step.apply(doc); err != nil {
+			return nil, fileVersion, f.errorf("upgrading %s to schema version %d (%s): %v", f.path, v+1, step.description, err)
+		}
+	}
+	doc["version"] = f.current
+	upgraded, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fileVersion, f.errorf("upgrading %s: %v", f.path, err)
+	}
+	return upgraded, fileVersion, nil
+}
+
+// backupSchemaFile copies the pre-migration contents of a file to backup,
+// once, and reports whether it did.
+func backupSchemaFile(backup string, raw []byte) (bool, error) {
+	if _, err := os.Stat(backup); err == nil {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(backup, raw, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// migrateConfig upgrades config.json in place, keeping the original as
+// config.json.v<N>.bak next to it.
+func migrateConfig(path string, raw []byte) ([]byte, error) {
+	f := schemaFile{name: "config.json", path: path, current: configSchemaVersion, migrations: configMigrations, errorf: configErrorf}
+	upgraded, from, err := upgradeSchema(f, raw)
+	if err != nil || from == configSchemaVersion {
+		return upgraded, err
+	}
+	backup := fmt.Sprintf("%s.v%d.bak", path, from)
+	if _, err := backupSchemaFile(backup, raw); err != nil {
+		return nil, configErrorf("backing up %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, upgraded, 0644); err != nil {
+		return nil, configErrorf("writing upgraded %s: %v", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "Note: upgraded %s from schema version %d to %d (backup: %s)\n", path, from, configSchemaVersion, backup)
+	return upgraded, nil
+}
+
+// migrateData upgrades progress.json in memory; the upgraded form is
+// written, and committed, with the next change. The original is kept in
+// the repo's .git/att-backups so it never ends up in a commit.
+func migrateData(dataPath, path string, raw []byte) ([]byte, error) {
+	f := schemaFile{name: "progress.json", path: path, current: dataSchemaVersion, migrations: dataMigrations, errorf: dataErrorf}
+	upgraded, from, err := upgradeSchema(f, raw)
+	if err != nil || from == dataSchemaVersion {
+		return upgraded, err
+	}
+	dir := filepath.Join(dataPath, ".git", "att-backups")
+	if _, err := os.Stat(filepath.Join(dataPath, ".git")); errors.Is(err, os.ErrNotExist) {
+		dir = dataPath
+	}
+	backup := filepath.Join(dir, fmt.Sprintf("progress.json.v%d.bak", from))
+	created, err := backupSchemaFile(backup, raw)
+	if err != nil {
+		return nil, dataErrorf("backing up %s: %v", path, err)
+	}
+	if created {
+		fmt.Fprintf(os.Stderr, "Note: upgrading %s from schema version %d to %d on the next save (backup: %s)\n", path, from, dataSchemaVersion, backup)
+	}
+	return upgraded, nil
+}
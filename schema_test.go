@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigMigratesHomeRelativeDataPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ATT_DATA", "")
+	t.Setenv("ATT_PROFILE", "")
+	configPath := filepath.Join(home, "config.json")
+	t.Setenv("ATT_CONFIG", configPath)
+	legacy := `{"version": 1, "data_path": "~/.att", "topics": {"dsa": {"name": "DSA", "daily_goal": 2, "enabled": true}}}`
+	if err := os.WriteFile(configPath, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, ".att"); cfg.DataPath != want {
+		t.Errorf("DataPath = %q, want %q", cfg.DataPath, want)
+	}
+	if tc := cfg.Topics["dsa"]; tc == nil || tc.Name != "DSA" || tc.DailyGoal != 2 {
+		t.Errorf("Topics = %+v, want dsa moved into the data repo", cfg.Topics)
+	}
+	if _, err := os.Stat(configPath + ".v1.bak"); err != nil {
+		t.Errorf("no backup of the version 1 config: %v", err)
+	}
+
+	// Loading again reads the upgraded config and the moved topics.
+	cfg, err = loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Topics["dsa"] == nil {
+		t.Errorf("Topics after reloading = %+v, want dsa", cfg.Topics)
+	}
+}
+
+func TestLoadConfigConcurrentWithDataOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ATT_PROFILE", "")
+	configPath := filepath.Join(home, "config.json")
+	t.Setenv("ATT_CONFIG", configPath)
+	t.Setenv("ATT_DATA", filepath.Join(home, "override"))
+	if err := os.WriteFile(configPath, []byte(`{"version": 2, "data_path": "/srv/att"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// `att serve` loads the config from concurrent requests.
+	done := make(chan error)
+	for i := 0; i < 8; i++ {
+		go func() {
+			_, err := loadConfig()
+			done <- err
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if err := <-done; err != nil {
+			t.Error(err)
+		}
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := saveConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if got := storedDataPath(configPath); got != "/srv/att" {
+		t.Errorf("saved data_path = %q, want the stored /srv/att rather than ATT_DATA", got)
+	}
+}
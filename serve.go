@@ -0,0 +1,306 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func newServeCommand() *command {
+	serveCmd := newCommand("serve", "", "Serve a local HTTP/JSON API")
+	serveCmd.maxArgs = 0
+	addr := serveCmd.flags.String("addr", "127.0.0.1:8787", "`address` to listen on; use 0.0.0.0:8787 to serve the LAN")
+	token := serveCmd.flags.String("token", "", "bearer `token` clients must send (default: $ATT_TOKEN, or a random one)")
+	serveCmd.examples = []string{
+		"att serve",
+		"ATT_TOKEN=s3cret att serve --addr 0.0.0.0:8787",
+		"curl -H 'Authorization: Bearer s3cret' -d '{\"topic\":\"dsa\",\"remark\":\"two pointers\"}' localhost:8787/api/checkins",
+	}
+	serveCmd.run = func([]string) error {
+		if _, err := requireConfig(); err != nil {
+			return err
+		}
+		tok := *token
+		if tok == "" {
+			tok = os.Getenv("ATT_TOKEN")
+		}
+		if tok == "" {
+			b := make([]byte, 16)
+			if _, err := rand.Read(b); err != nil {
+				return err
+			}
+			tok = hex.EncodeToString(b)
+			fmt.Printf("Token: %s\n", tok)
+		}
+
+		srv := &http.Server{
+			Addr:              *addr,
+			Handler:           newAPIHandler(tok),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		fmt.Printf("Serving the att API on http://%s/api/ (Ctrl+C to stop)\n", *addr)
+		return srv.ListenAndServe()
+	}
+	return serveCmd
+}
+
+// newAPIHandler routes the API. Every request re-reads config.json and
+// progress.json, so changes made with the CLI while the server runs are
+// picked up, and every mutation goes through the same locked, committed
+// code paths as the CLI.
+func newAPIHandler(token string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/topics", apiTopics)
+	mux.HandleFunc("GET /api/topics/{topic}", apiTopic)
+	mux.HandleFunc("GET /api/checkins", apiListCheckins)
+	mux.HandleFunc("POST /api/checkins", apiCreateCheckin)
+	mux.HandleFunc("DELETE /api/checkins/{id}", apiDeleteCheckin)
+	mux.HandleFunc("GET /api/status", apiStatus)
+	mux.HandleFunc("GET /api/stats", apiStats)
+	mux.HandleFunc("GET /metrics", serveMetrics)
+	return requireToken(token, mux)
+}
+
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="att"`)
+			writeAPIError(w, http.StatusUnauthorized, errors.New("missing or invalid bearer token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+// apiFail maps CLI error classes onto HTTP status codes.
+func apiFail(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch exitCode(err) {
+	case exitUsage:
+		code = http.StatusBadRequest
+	case exitNotFound:
+		code = http.StatusNotFound
+	case exitConfig:
+		code = http.StatusConflict
+	}
+	writeAPIError(w, code, err)
+}
+
+func apiTopics(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requireConfig()
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	checkStreaks(data)
+	writeJSON(w, http.StatusOK, topicStatuses(cfg, data))
+}
+
+func apiTopic(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requireConfig()
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	topicID, err := resolveTopic(cfg.Topics, r.PathValue("topic"))
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	checkStreaks(data)
+	writeJSON(w, http.StatusOK, newTopicStatus(topicID, cfg.Topics[topicID], data))
+}
+
+// apiListCheckins accepts the same filters as `att log`: topic, since,
+// until, grep and limit.
+func apiListCheckins(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requireConfig()
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	q := r.URL.Query()
+	var f checkInFilter
+	if topic := q.Get("topic"); topic != "" {
+		if f.topicID, err = resolveTopic(cfg.Topics, topic); err != nil {
+			apiFail(w, err)
+			return
+		}
+	}
+	now := time.Now()
+	if since := q.Get("since"); since != "" {
+		if f.since, err = parseDay(since, now); err != nil {
+			apiFail(w, err)
+			return
+		}
+	}
+	if until := q.Get("until"); until != "" {
+		day, err := parseDay(until, now)
+		if err != nil {
+			apiFail(w, err)
+			return
+		}
+		f.until = day.AddDate(0, 0, 1)
+	}
+	if grep := q.Get("grep"); grep != "" {
+		if f.grep, err = regexp.Compile("(?i)" + grep); err != nil {
+			apiFail(w, usageErrorf("invalid grep pattern: %v", err))
+			return
+		}
+	}
+	limit := 0
+	if s := q.Get("limit"); s != "" {
+		if limit, err = strconv.Atoi(s); err != nil || limit < 0 {
+			apiFail(w, usageErrorf("invalid limit %q", s))
+			return
+		}
+	}
+
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	entries := collectCheckIns(cfg, data, f)
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	if entries == nil {
+		entries = []checkInEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+// apiCreateCheckin records a check-in from {"topic": ..., "remark": ...}
+// and responds with the `att checkin --json` report.
+func apiCreateCheckin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Topic  string `json:"topic"`
+		Remark string `json:"remark"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		apiFail(w, usageErrorf("invalid JSON body: %v", err))
+		return
+	}
+	req.Remark = strings.TrimSpace(req.Remark)
+	if req.Topic == "" || req.Remark == "" {
+		apiFail(w, usageErrorf("topic and remark are required"))
+		return
+	}
+
+	cfg, err := requireConfig()
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	topicID, err := resolveTopic(cfg.Topics, req.Topic)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	topicCfg := cfg.Topics[topicID]
+	if !topicCfg.Enabled {
+		apiFail(w, configErrorf("topic '%s' is disabled", topicID))
+		return
+	}
+	topicData, progress, err := recordCheckin(cfg, topicID, req.Remark)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newCheckinReport(topicID, topicCfg, topicData, progress, req.Remark))
+}
+
+func apiDeleteCheckin(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requireConfig()
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	entry, err := deleteCheckin(cfg, r.PathValue("id"))
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+func apiStatus(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requireConfig()
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	checkStreaks(data)
+	writeJSON(w, http.StatusOK, statusReport{Date: todayString(), Topics: topicStatuses(cfg, data)})
+}
+
+// apiStats accepts topic and period like `att stats`.
+func apiStats(w http.ResponseWriter, r *http.Request) {
+	cfg, err := requireConfig()
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	q := r.URL.Query()
+	period := q.Get("period")
+	if period == "" {
+		period = "30d"
+	}
+	now := time.Now()
+	p, err := parsePeriod(period, now)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	topicID := ""
+	if topic := q.Get("topic"); topic != "" {
+		if topicID, err = resolveTopic(cfg.Topics, topic); err != nil {
+			apiFail(w, err)
+			return
+		}
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		apiFail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, computeStats(cfg, data, topicID, p, now))
+}
@@ -0,0 +1,384 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"att/model"
+)
+
+func newSiteCommand() *command {
+	siteCmd := newCommand("site", "", "Generate a static progress website")
+	siteCmd.add(newSiteBuildCommand())
+	return siteCmd
+}
+
+func newSiteBuildCommand() *command {
+	buildCmd := newCommand("build", "<dir>", "Build the site into a directory")
+	buildCmd.minArgs, buildCmd.maxArgs = 1, 1
+	title := buildCmd.flags.String("title", "Progress", "site `title`")
+	baseURL := buildCmd.flags.String("base-url", "", "public `URL` the site is served from, used for absolute feed links")
+	feedLimit := buildCmd.flags.Int("feed-limit", 50, "number of recent check-ins in the feed")
+	buildCmd.examples = []string{
+		"att site build public/",
+		"att site build ~/.att/site --title \"Alex's habits\" --base-url https://alex.example/habits/",
+	}
+	buildCmd.run = func(args []string) error {
+		if *feedLimit < 0 {
+			return usageErrorf("--feed-limit must not be negative")
+		}
+		cfg, err := requireConfig()
+		if err != nil {
+			return err
+		}
+		data, err := readData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+		dir := expandHome(args[0])
+		s := site{
+			Title:     *title,
+			BaseURL:   strings.TrimSuffix(*baseURL, "/"),
+			Generated: time.Now(),
+		}
+		if err := s.build(dir, cfg, data, *feedLimit); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Built %d topic pages in %s\n", len(s.Topics), args[0])
+		return nil
+	}
+	return buildCmd
+}
+
+// site is the template data shared by every page. Pages are written with
+// inline styles and SVG so the result works offline from any static host.
+type site struct {
+	Title     string
+	BaseURL   string
+	Generated time.Time
+	Topics    []*siteTopic
+	Recent    []siteEntry
+}
+
+type siteTopic struct {
+	ID      string
+	Name    string
+	Emoji   string
+	Page    string // relative to the site root
+	Goal    int
+	Enabled bool
+	Total   int
+	Today   int
+	Current int
+	Longest int
+	LastDay string
+	Heatmap template.HTML
+	Days    []siteDay
+}
+
+type siteDay struct {
+	Label   string
+	Count   int
+	Entries []siteEntry
+}
+
+type siteEntry struct {
+	ID     string
+	Topic  *siteTopic
+	Time   time.Time
+	Remark string
+}
+
+func (s *site) build(dir string, cfg *model.Config, data *ProgressData, feedLimit int) error {
+	entries := collectCheckIns(cfg, data, checkInFilter{})
+	byTopic := make(map[string][]checkInEntry)
+	for _, e := range entries {
+		byTopic[e.TopicID] = append(byTopic[e.TopicID], e)
+	}
+
+	today := dayKey(s.Generated)
+	pages := make(map[string]bool)
+	topics := make(map[string]*siteTopic)
+	for _, id := range sortedTopicIDs(cfg.Topics) {
+		tc := cfg.Topics[id]
+		if !tc.Enabled && len(byTopic[id]) == 0 {
+			continue
+		}
+		counts := dailyCounts(data.Topics[id])
+		t := &siteTopic{
+			ID:      id,
+			Name:    tc.Name,
+			Emoji:   tc.Emoji,
+			Page:    "topics/" + uniquePageName(id, pages) + ".html",
+			Goal:    tc.DailyGoal,
+			Enabled: tc.Enabled,
+			Total:   len(byTopic[id]),
+			Today:   counts[today],
+			Heatmap: heatmapSVG(counts, tc.DailyGoal, s.Generated),
+		}
+		t.Current, t.Longest = historyStreaks(counts, s.Generated)
+		if len(byTopic[id]) > 0 {
+			t.LastDay = dayKey(byTopic[id][0].time)
+		}
+		s.Topics = append(s.Topics, t)
+		topics[id] = t
+	}
+
+	// Entries are newest first, which is the order pages and the feed use.
+	for _, e := range entries {
+		t := topics[e.TopicID]
+		if t == nil {
+			continue
+		}
+		se := siteEntry{ID: e.ID, Topic: t, Time: e.time.Local(), Remark: e.Remark}
+		if len(s.Recent) < feedLimit {
+			s.Recent = append(s.Recent, se)
+		}
+		label := se.Time.Format("Monday 2 January 2006")
+		if n := len(t.Days); n == 0 || t.Days[n-1].Label != label {
+			t.Days = append(t.Days, siteDay{Label: label})
+		}
+		day := &t.Days[len(t.Days)-1]
+		day.Count++
+		day.Entries = append(day.Entries, se)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "topics"), 0755); err != nil {
+		return dataErrorf("creating site directory: %v", err)
+	}
+	if err := s.render(filepath.Join(dir, "index.html"), "index", struct {
+		*site
+		Root string
+	}{s, ""}); err != nil {
+		return err
+	}
+	for _, t := range s.Topics {
+		if err := s.render(filepath.Join(dir, t.Page), "topic", struct {
+			*site
+			Root  string
+			Topic *siteTopic
+		}{s, "../", t}); err != nil {
+			return err
+		}
+	}
+	return s.writeFeed(filepath.Join(dir, "feed.xml"))
+}
+
+// uniquePageName derives a file name from a topic ID, which may contain
+// characters that are awkward in paths and URLs.
+func uniquePageName(id string, used map[string]bool) string {
+	base := topicSlug(id)
+	if base == "" {
+		base = "topic"
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = base + "-" + strconv.Itoa(i)
+	}
+	used[name] = true
+	return name
+}
+
+func (s *site) render(path, name string, data any) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return dataErrorf("writing site: %v", err)
+	}
+	if err := siteTemplates.ExecuteTemplate(f, name, data); err != nil {
+		f.Close()
+		return dataErrorf("rendering %s: %v", filepath.Base(path), err)
+	}
+	if err := f.Close(); err != nil {
+		return dataErrorf("writing site: %v", err)
+	}
+	return nil
+}
+
+// heatmapSVG draws the last 53 weeks of a topic's daily counts as a
+// calendar grid, one column per week starting on Monday.
+func heatmapSVG(counts map[string]int, goal int, now time.Time) template.HTML {
+	const weeks, cell, gap = 53, 11, 2
+	today := startOfDay(now)
+	offset := (int(today.Weekday()) + 6) % 7 // days since Monday
+	start := today.AddDate(0, 0, -offset-7*(weeks-1))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="heatmap" viewBox="0 0 %d %d" role="img" aria-label="Check-ins over the last year">`,
+		weeks*(cell+gap), 7*(cell+gap))
+	for day := start; !day.After(today); day = day.AddDate(0, 0, 1) {
+		i := int(day.Sub(start).Hours()/24 + 0.5)
+		n := counts[dayKey(day)]
+		level := 0
+		switch {
+		case n == 0:
+		case goal > 0 && n >= 2*goal:
+			level = 3
+		case n >= goal:
+			level = 2
+		default:
+			level = 1
+		}
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="2" class="l%d"><title>%s: %d</title></rect>`,
+			(i/7)*(cell+gap), (i%7)*(cell+gap), cell, cell, level, dayKey(day), n)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// The feed is Atom, which readers treat the same as RSS and which has an
+// unambiguous date format.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Author  string   `xml:"author>name"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary,omitempty"`
+}
+
+func (s *site) writeFeed(path string) error {
+	feed := atomFeed{
+		Title:   s.Title,
+		ID:      "urn:att:site:" + topicSlug(s.Title),
+		Updated: s.Generated.Format(time.RFC3339),
+	}
+	if s.BaseURL != "" {
+		feed.ID = s.BaseURL + "/"
+		feed.Links = []atomLink{{Rel: "self", Href: s.BaseURL + "/feed.xml"}, {Href: s.BaseURL + "/"}}
+	}
+	if len(s.Recent) > 0 {
+		feed.Updated = s.Recent[0].Time.Format(time.RFC3339)
+	}
+
+	seen := make(map[string]int)
+	for _, e := range s.Recent {
+		id := e.ID
+		if n := seen[e.ID]; n > 0 {
+			id = fmt.Sprintf("%s-%d", e.ID, n)
+		}
+		seen[e.ID]++
+		href := e.Topic.Page
+		if s.BaseURL != "" {
+			href = s.BaseURL + "/" + href
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   strings.TrimSpace(fmt.Sprintf("%s %s · %s", e.Topic.Emoji, e.Topic.Name, e.Time.Format("2 Jan 15:04"))),
+			ID:      "urn:att:checkin:" + id,
+			Updated: e.Time.Format(time.RFC3339),
+			Author:  "att",
+			Link:    atomLink{Href: href},
+			Summary: e.Remark,
+		})
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return dataErrorf("encoding feed: %v", err)
+	}
+	if err := os.WriteFile(path, append([]byte(xml.Header), append(out, '\n')...), 0644); err != nil {
+		return dataErrorf("writing feed: %v", err)
+	}
+	return nil
+}
+
+var siteTemplates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("2006-01-02") },
+	"time": func(t time.Time) string { return t.Format("15:04") },
+}).Parse(`
+{{define "foot"}}
+<footer class="muted"><p>Generated {{.Generated.Format "2006-01-02 15:04"}} · <a href="{{.Root}}feed.xml">Atom feed</a></p></footer>
+</body>
+</html>
+{{end}}
+
+{{define "stats"}}<div class="stats">
+<div><b>{{.Today}}/{{.Goal}}</b><span class="muted">today</span></div>
+<div><b>{{.Current}}</b><span class="muted">day streak</span></div>
+<div><b>{{.Longest}}</b><span class="muted">longest streak</span></div>
+<div><b>{{.Total}}</b><span class="muted">check-ins</span></div>
+</div>{{end}}
+
+{{define "index"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="alternate" type="application/atom+xml" title="Recent check-ins" href="feed.xml">
+{{template "style"}}
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="muted">{{len .Topics}} topics</p>
+{{range .Topics}}<section class="topic{{if not .Enabled}} disabled{{end}}">
+<h2><a href="{{.Page}}">{{.Emoji}} {{.Name}}</a></h2>
+{{template "stats" .}}
+{{.Heatmap}}
+</section>
+{{else}}<p>No topics yet.</p>
+{{end}}
+{{if .Recent}}<h2>Recent check-ins</h2>
+<table>
+{{range .Recent}}<tr><td class="time">{{date .Time}}</td><td><a href="{{.Topic.Page}}">{{.Topic.Emoji}} {{.Topic.Name}}</a></td><td>{{.Remark}}</td></tr>
+{{end}}</table>
+{{end}}
+{{template "foot" .}}{{end}}
+
+{{define "topic"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Topic.Name}} · {{.Title}}</title>
+<link rel="alternate" type="application/atom+xml" title="Recent check-ins" href="../feed.xml">
+{{template "style"}}
+</head>
+<body>
+<p><a href="../index.html">← {{.Title}}</a></p>
+{{with .Topic}}<h1>{{.Emoji}} {{.Name}}</h1>
+<p class="muted">Goal {{.Goal}}/day{{if .LastDay}} · last check-in {{.LastDay}}{{end}}{{if not .Enabled}} · disabled{{end}}</p>
+{{template "stats" .}}
+{{.Heatmap}}
+<h2>History</h2>
+{{range .Days}}<h3>{{.Label}} <span class="muted">({{.Count}})</span></h3>
+<table>
+{{range .Entries}}<tr><td class="time">{{time .Time}}</td><td>{{.Remark}}</td></tr>
+{{end}}</table>
+{{else}}<p>No check-ins yet.</p>
+{{end}}{{end}}
+{{template "foot" .}}{{end}}
+
+{{define "style"}}<style>
+body{font-family:system-ui,-apple-system,sans-serif;max-width:60rem;margin:2rem auto;padding:0 1rem;color:#111827;background:#fff}
+a{color:#7C3AED;text-decoration:none}a:hover{text-decoration:underline}
+h1{margin-bottom:.25rem}.muted{color:#6B7280;font-weight:normal}
+.topic{border:1px solid #E5E7EB;border-radius:8px;padding:1rem;margin:1rem 0}
+.topic h2{margin:0 0 .5rem}.stats{display:flex;gap:1.5rem;flex-wrap:wrap;margin:.5rem 0 1rem}
+.stats b{display:block;font-size:1.4rem}
+.heatmap{width:100%;height:auto}.heatmap .l0{fill:#EBEDF0}.heatmap .l1{fill:#A7F3D0}.heatmap .l2{fill:#10B981}.heatmap .l3{fill:#047857}
+.disabled{opacity:.6}
+table{border-collapse:collapse;width:100%}td{padding:.25rem .5rem;vertical-align:top;border-bottom:1px solid #F3F4F6}
+td.time{width:6rem;color:#6B7280;font-variant-numeric:tabular-nums;white-space:nowrap}
+@media (prefers-color-scheme:dark){body{background:#111827;color:#F3F4F6}.topic{border-color:#374151}.heatmap .l0{fill:#1F2937}td{border-color:#1F2937}}
+</style>{{end}}
+`))
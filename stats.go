@@ -0,0 +1,415 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"att/model"
+	"att/ui"
+)
+
+// statsPeriod is a span of whole local days, together with the span it is
+// compared against.
+type statsPeriod struct {
+	label     string
+	start     time.Time
+	days      int
+	prevStart time.Time
+	prevDays  int
+}
+
+// parsePeriod understands "30d", "4w", "week", "month" and "year". Calendar
+// periods run to date and are compared with the same number of days at the
+// start of the previous week, month or year.
+func parsePeriod(s string, now time.Time) (statsPeriod, error) {
+	today := startOfDay(now)
+	elapsed := func(start time.Time) int {
+		return int(math.Round(today.Sub(start).Hours()/24)) + 1
+	}
+
+	switch s {
+	case "week":
+		start := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		days := elapsed(start)
+		return statsPeriod{label: "this week", start: start, days: days, prevStart: start.AddDate(0, 0, -7), prevDays: days}, nil
+	case "month":
+		start := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.Local)
+		prevStart := start.AddDate(0, -1, 0)
+		days := elapsed(start)
+		return statsPeriod{label: "this month", start: start, days: days, prevStart: prevStart, prevDays: min(days, start.AddDate(0, 0, -1).Day())}, nil
+	case "year":
+		start := time.Date(today.Year(), 1, 1, 0, 0, 0, 0, time.Local)
+		prevStart := start.AddDate(-1, 0, 0)
+		days := elapsed(start)
+		return statsPeriod{label: "this year", start: start, days: days, prevStart: prevStart, prevDays: min(days, start.AddDate(0, 0, -1).YearDay())}, nil
+	}
+
+	if n, unit, ok := splitRelative(s); ok && n > 0 && (unit == "d" || unit == "w") {
+		days := n
+		if unit == "w" {
+			days *= 7
+		}
+		start := today.AddDate(0, 0, -(days - 1))
+		return statsPeriod{label: fmt.Sprintf("last %d days", days), start: start, days: days, prevStart: start.AddDate(0, 0, -days), prevDays: days}, nil
+	}
+	return statsPeriod{}, usageErrorf("invalid period %q: use 30d, 4w, week, month or year", s)
+}
+
+// dayKey identifies a local calendar day.
+func dayKey(t time.Time) string {
+	return t.Local().Format("2006-01-02")
+}
+
+// dailyCounts returns the number of check-ins per local day.
+func dailyCounts(topicData *TopicData) map[string]int {
+	counts := make(map[string]int)
+	if topicData == nil {
+		return counts
+	}
+	for _, ci := range topicData.History {
+		if t, err := time.Parse(time.RFC3339, ci.Date); err == nil {
+			counts[dayKey(t)]++
+		}
+	}
+	return counts
+}
+
+// historyStreaks is the streak rule shared by the dashboard, stats, badges,
+// metrics and the site: a streak is a run of consecutive local days with at
+// least one check-in, and it is current if it reaches today or yesterday.
+func historyStreaks(counts map[string]int, now time.Time) (current, longest int) {
+	var days []time.Time
+	for key := range counts {
+		if d, err := time.ParseInLocation("2006-01-02", key, time.Local); err == nil {
+			days = append(days, d)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	run := 0
+	for i, d := range days {
+		if i > 0 && dayKey(days[i-1].AddDate(0, 0, 1)) == dayKey(d) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+
+	if len(days) > 0 {
+		today := startOfDay(now)
+		last := dayKey(days[len(days)-1])
+		if last == dayKey(today) || last == dayKey(today.AddDate(0, 0, -1)) {
+			current = run
+		}
+	}
+	return current, longest
+}
+
+// currentStreak returns topicData's current streak as of now, computed
+// from its history by historyStreaks.
+func currentStreak(topicData *TopicData, now time.Time) int {
+	current, _ := historyStreaks(dailyCounts(topicData), now)
+	return current
+}
+
+// periodStats summarises check-ins over a span of days. It is part of the
+// documented `att stats` output schema.
+type periodStats struct {
+	Start           string  `json:"start"`
+	End             string  `json:"end"`
+	Days            int     `json:"days"`
+	CheckIns        int     `json:"checkins"`
+	ActiveDays      int     `json:"active_days"`
+	GoalDays        int     `json:"goal_days"`
+	CompletionRate  float64 `json:"completion_rate"`
+	AvgPerActiveDay float64 `json:"avg_per_active_day"`
+}
+
+type topicStats struct {
+	ID            string      `json:"id"`
+	Name          string      `json:"name"`
+	Emoji         string      `json:"emoji"`
+	DailyGoal     int         `json:"daily_goal"`
+	Current       periodStats `json:"current"`
+	Previous      periodStats `json:"previous"`
+	CurrentStreak int         `json:"current_streak"`
+	LongestStreak int         `json:"longest_streak"`
+}
+
+type statsReport struct {
+	Period        string       `json:"period"`
+	Topic         string       `json:"topic,omitempty"`
+	Current       periodStats  `json:"current"`
+	Previous      periodStats  `json:"previous"`
+	CurrentStreak int          `json:"current_streak"`
+	LongestStreak int          `json:"longest_streak"`
+	ByWeekday     []int        `json:"by_weekday"`
+	ByHour        []int        `json:"by_hour"`
+	Topics        []topicStats `json:"topics"`
+}
+
+// computePeriodStats aggregates the given topics over days starting at
+// start. A day counts towards GoalDays when every topic met its goal.
+func computePeriodStats(ids []string, cfg *model.Config, counts map[string]map[string]int, start time.Time, days int) periodStats {
+	ps := periodStats{
+		Start: dayKey(start),
+		End:   dayKey(start.AddDate(0, 0, days-1)),
+		Days:  days,
+	}
+	achieved, possible := 0, 0
+	for d := 0; d < days; d++ {
+		key := dayKey(start.AddDate(0, 0, d))
+		active, allMet := false, len(ids) > 0
+		for _, id := range ids {
+			goal := cfg.Topics[id].DailyGoal
+			c := counts[id][key]
+			ps.CheckIns += c
+			achieved += min(c, goal)
+			possible += goal
+			if c > 0 {
+				active = true
+			}
+			if c < goal {
+				allMet = false
+			}
+		}
+		if active {
+			ps.ActiveDays++
+		}
+		if allMet {
+			ps.GoalDays++
+		}
+	}
+	if possible > 0 {
+		ps.CompletionRate = roundTo(float64(achieved)/float64(possible), 3)
+	}
+	if ps.ActiveDays > 0 {
+		ps.AvgPerActiveDay = roundTo(float64(ps.CheckIns)/float64(ps.ActiveDays), 2)
+	}
+	return ps
+}
+
+func roundTo(f float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(f*p) / p
+}
+
+// computeStats builds the report for topicID, or for every enabled topic
+// when topicID is empty.
+func computeStats(cfg *model.Config, data *ProgressData, topicID string, period statsPeriod, now time.Time) statsReport {
+	var ids []string
+	if topicID != "" {
+		ids = []string{topicID}
+	} else {
+		for _, id := range sortedTopicIDs(cfg.Topics) {
+			if cfg.Topics[id].Enabled {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	counts := make(map[string]map[string]int)
+	union := make(map[string]int)
+	for _, id := range ids {
+		counts[id] = dailyCounts(data.Topics[id])
+		for k, v := range counts[id] {
+			union[k] += v
+		}
+	}
+
+	report := statsReport{
+		Period:    period.label,
+		Topic:     topicID,
+		Current:   computePeriodStats(ids, cfg, counts, period.start, period.days),
+		Previous:  computePeriodStats(ids, cfg, counts, period.prevStart, period.prevDays),
+		ByWeekday: make([]int, 7),
+		ByHour:    make([]int, 24),
+		Topics:    []topicStats{},
+	}
+	report.CurrentStreak, report.LongestStreak = historyStreaks(union, now)
+
+	end := period.start.AddDate(0, 0, period.days)
+	for _, id := range ids {
+		if topicData := data.Topics[id]; topicData != nil {
+			for _, ci := range topicData.History {
+				t, err := time.Parse(time.RFC3339, ci.Date)
+				if err != nil || t.Before(period.start) || !t.Before(end) {
+					continue
+				}
+				t = t.Local()
+				report.ByWeekday[(int(t.Weekday())+6)%7]++
+				report.ByHour[t.Hour()]++
+			}
+		}
+
+		tc := cfg.Topics[id]
+		ts := topicStats{
+			ID:        id,
+			Name:      tc.Name,
+			Emoji:     tc.Emoji,
+			DailyGoal: tc.DailyGoal,
+			Current:   computePeriodStats([]string{id}, cfg, counts, period.start, period.days),
+			Previous:  computePeriodStats([]string{id}, cfg, counts, period.prevStart, period.prevDays),
+		}
+		ts.CurrentStreak, ts.LongestStreak = historyStreaks(counts[id], now)
+		report.Topics = append(report.Topics, ts)
+	}
+	return report
+}
+
+var topicStatsHeader = []string{"id", "name", "daily_goal", "checkins", "active_days", "goal_days", "completion_rate",
+	"avg_per_active_day", "current_streak", "longest_streak", "prev_checkins", "prev_completion_rate"}
+
+func (t topicStats) tsvRow() []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{t.ID, t.Name, strconv.Itoa(t.DailyGoal), strconv.Itoa(t.Current.CheckIns),
+		strconv.Itoa(t.Current.ActiveDays), strconv.Itoa(t.Current.GoalDays), f(t.Current.CompletionRate),
+		f(t.Current.AvgPerActiveDay), strconv.Itoa(t.CurrentStreak), strconv.Itoa(t.LongestStreak),
+		strconv.Itoa(t.Previous.CheckIns), f(t.Previous.CompletionRate)}
+}
+
+func newStatsCommand() *command {
+	statsCmd := newCommand("stats", "[topic]", "Show completion, activity and streak analytics")
+	statsCmd.maxArgs = 1
+	statsCmd.topicArg = anyTopicArg
+	statsFormat := addFormatFlags(statsCmd)
+	period := statsCmd.flags.String("period", "30d", "`period` to analyse: Nd, Nw, week, month or year")
+	statsCmd.examples = []string{
+		"att stats",
+		"att stats dsa --period week",
+		"att stats --period year --json",
+	}
+	statsCmd.run = func(args []string) error {
+		format, err := statsFormat()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		p, err := parsePeriod(*period, now)
+		if err != nil {
+			return err
+		}
+		cfg, err := requireConfig()
+		if err != nil {
+			return err
+		}
+		topicID := ""
+		if len(args) > 0 {
+			if topicID, err = resolveTopic(cfg.Topics, args[0]); err != nil {
+				return err
+			}
+		}
+		data, err := readData(cfg.DataPath)
+		if err != nil {
+			return err
+		}
+
+		report := computeStats(cfg, data, topicID, p, now)
+		if format != formatText {
+			var rows [][]string
+			for _, ts := range report.Topics {
+				rows = append(rows, ts.tsvRow())
+			}
+			return writeStructured(os.Stdout, format, report, topicStatsHeader, rows)
+		}
+		showStats(cfg, report)
+		return nil
+	}
+	return statsCmd
+}
+
+func showStats(cfg *model.Config, r statsReport) {
+	labelStyle := lipgloss.NewStyle().Foreground(ui.MutedColor).Width(16)
+	barStyle := lipgloss.NewStyle().Foreground(ui.PrimaryColor)
+
+	scope := "All topics"
+	if r.Topic != "" {
+		tc := cfg.Topics[r.Topic]
+		scope = fmt.Sprintf("%s %s", tc.Emoji, tc.Name)
+	}
+
+	fmt.Println()
+	fmt.Println(ui.TitleStyle.Render(fmt.Sprintf("📊 %s · %s", scope, r.Period)))
+	fmt.Println(ui.SubtitleStyle.Render(fmt.Sprintf("%s → %s, compared with %s → %s",
+		r.Current.Start, r.Current.End, r.Previous.Start, r.Previous.End)))
+	fmt.Println()
+
+	cur, prev := r.Current, r.Previous
+	row := func(label, value, delta string) {
+		fmt.Printf("  %s %-14s %s\n", labelStyle.Render(label), value, delta)
+	}
+	row("Completion", fmt.Sprintf("%.0f%%", cur.CompletionRate*100),
+		formatDelta((cur.CompletionRate-prev.CompletionRate)*100, " pts"))
+	row("Check-ins", strconv.Itoa(cur.CheckIns), formatDelta(float64(cur.CheckIns-prev.CheckIns), ""))
+	row("Active days", fmt.Sprintf("%d/%d", cur.ActiveDays, cur.Days), formatDelta(float64(cur.ActiveDays-prev.ActiveDays), ""))
+	row("Goal met", fmt.Sprintf("%d days", cur.GoalDays), formatDelta(float64(cur.GoalDays-prev.GoalDays), ""))
+	row("Per active day", fmt.Sprintf("%.1f", cur.AvgPerActiveDay), formatDelta(cur.AvgPerActiveDay-prev.AvgPerActiveDay, ""))
+	row("Streak", fmt.Sprintf("%d days", r.CurrentStreak), fmt.Sprintf("(longest %d)", r.LongestStreak))
+
+	fmt.Println()
+	fmt.Println(ui.TopicStyle.MarginTop(0).Render("By weekday"))
+	peak := 0
+	for _, n := range r.ByWeekday {
+		peak = max(peak, n)
+	}
+	for i, day := range []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"} {
+		width := 0
+		if peak > 0 {
+			width = r.ByWeekday[i] * 30 / peak
+		}
+		fmt.Printf("  %s %s %d\n", day, barStyle.Render(strings.Repeat("█", width)), r.ByWeekday[i])
+	}
+
+	fmt.Println()
+	fmt.Println(ui.TopicStyle.MarginTop(0).Render("By hour"))
+	fmt.Printf("  %s\n", barStyle.Render(sparkline(r.ByHour)))
+	fmt.Println(lipgloss.NewStyle().Foreground(ui.MutedColor).Render("  0     6     12    18   23"))
+
+	if r.Topic == "" && len(r.Topics) > 0 {
+		fmt.Println()
+		fmt.Println(ui.TopicStyle.MarginTop(0).Render("By topic"))
+		for _, ts := range r.Topics {
+			fmt.Printf("  %-24s %4.0f%%  %3d check-ins  🔥 %d (best %d)\n",
+				fmt.Sprintf("%s %s", ts.Emoji, ts.Name), ts.Current.CompletionRate*100,
+				ts.Current.CheckIns, ts.CurrentStreak, ts.LongestStreak)
+		}
+	}
+	fmt.Println()
+}
+
+func formatDelta(d float64, unit string) string {
+	switch {
+	case d > 0.05:
+		return lipgloss.NewStyle().Foreground(ui.SuccessColor).
+			Render(fmt.Sprintf("▲ %s%s", strconv.FormatFloat(roundTo(d, 1), 'f', -1, 64), unit))
+	case d < -0.05:
+		return lipgloss.NewStyle().Foreground(ui.DangerColor).
+			Render(fmt.Sprintf("▼ %s%s", strconv.FormatFloat(roundTo(-d, 1), 'f', -1, 64), unit))
+	}
+	return lipgloss.NewStyle().Foreground(ui.MutedColor).Render("= no change")
+}
+
+func sparkline(values []int) string {
+	levels := []rune("▁▂▃▄▅▆▇█")
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		if v == 0 {
+			b.WriteRune(' ')
+		} else {
+			b.WriteRune(levels[(v*(len(levels)-1))/peak])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCurrentStreakUsesLocalDays(t *testing.T) {
+	// Ten hours ahead of UTC, early-morning check-ins fall on the previous
+	// UTC day, so UTC days would disagree with the local calendar.
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.FixedZone("AEST", 10*60*60)
+	at := func(d, h int) string {
+		return time.Date(2025, 1, d, h, 0, 0, 0, time.Local).Format(time.RFC3339)
+	}
+	now := time.Date(2025, 1, 5, 9, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name    string
+		history []string
+		current int
+		longest int
+	}{
+		{"empty", nil, 0, 0},
+		{"today only", []string{at(5, 7)}, 1, 1},
+		{"through yesterday", []string{at(3, 7), at(4, 23)}, 2, 2},
+		{"morning and evening are one day", []string{at(4, 1), at(4, 23), at(5, 8)}, 2, 2},
+		{"early mornings on consecutive days", []string{at(3, 6), at(4, 6), at(5, 6)}, 3, 3},
+		{"gap breaks the streak", []string{at(1, 8), at(2, 8), at(4, 8), at(5, 8)}, 2, 2},
+		{"ended before yesterday", []string{at(1, 8), at(2, 8), at(3, 8)}, 0, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			td := &TopicData{}
+			for _, d := range tt.history {
+				td.History = append(td.History, CheckIn{Date: d})
+			}
+			if got := currentStreak(td, now); got != tt.current {
+				t.Errorf("currentStreak = %d, want %d", got, tt.current)
+			}
+			if _, longest := historyStreaks(dailyCounts(td), now); longest != tt.longest {
+				t.Errorf("longest streak = %d, want %d", longest, tt.longest)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"att/ui"
+)
+
+func newStatusCommand() *command {
+	statusCmd := newCommand("status", "", "Show today's progress for every topic")
+	statusCmd.maxArgs = 0
+	statusFormat := addFormatFlags(statusCmd)
+	template := statusCmd.flags.String("template", "", "format each topic with `tmpl` on a single line; fields: {id} {name} {emoji} {done} {goal} {left} {streak} {total} {check}")
+	separator := statusCmd.flags.String("separator", " ", "text placed between topics when using --template")
+	incomplete := statusCmd.flags.Bool("incomplete", false, "only show enabled topics below today's goal")
+	statusCmd.examples = []string{
+		"att status",
+		"att status --json",
+		"att status --incomplete --template '{emoji}{done}/{goal}'",
+		"PS1='$(att status --template \"{emoji}{left}\" --incomplete 2>/dev/null) \\$ '",
+		"set -g status-right '#(att status --template \"{emoji}{done}/{goal}\")'   # tmux",
+	}
+	statusCmd.run = func([]string) error {
+		format, err := statusFormat()
+		if err != nil {
+			return err
+		}
+		if *template != "" && format != formatText {
+			return usageErrorf("--template cannot be combined with --format")
+		}
+		return showStatus(format, *template, *separator, *incomplete)
+	}
+	return statusCmd
+}
+
+// showStatus only reads config.json and progress.json; it never runs git,
+// so it is cheap enough to call from a shell prompt or tmux status bar.
+func showStatus(format, template, separator string, incompleteOnly bool) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	data, err := readData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	checkStreaks(data)
+
+	report := statusReport{
+		Date:   todayString(),
+		Topics: topicStatuses(cfg, data),
+	}
+	if incompleteOnly {
+		pending := []topicStatus{}
+		for _, st := range report.Topics {
+			if st.Enabled && !st.GoalMet {
+				pending = append(pending, st)
+			}
+		}
+		report.Topics = pending
+	}
+
+	if template != "" {
+		var parts []string
+		for _, st := range report.Topics {
+			if st.Enabled {
+				parts = append(parts, expandStatusTemplate(template, st))
+			}
+		}
+		if len(parts) > 0 {
+			fmt.Println(strings.Join(parts, separator))
+		}
+		return nil
+	}
+
+	if format != formatText {
+		var rows [][]string
+		for _, st := range report.Topics {
+			rows = append(rows, st.tsvRow())
+		}
+		return writeStructured(os.Stdout, format, report, topicStatusHeader, rows)
+	}
+
+	if len(report.Topics) == 0 {
+		if incompleteOnly {
+			fmt.Println("All goals met for today 🎉")
+		} else {
+			fmt.Println("No topics configured")
+		}
+		return nil
+	}
+
+	for _, st := range report.Topics {
+		label := fmt.Sprintf("%s %s", st.Emoji, st.Name)
+		if !st.Enabled {
+			fmt.Println(ui.DisabledStyle.Render(label))
+			continue
+		}
+
+		bar := strings.Repeat("█", min(st.Today, st.DailyGoal)) + strings.Repeat("░", max(st.DailyGoal-st.Today, 0))
+		progress := fmt.Sprintf("%d/%d [%s]", st.Today, st.DailyGoal, bar)
+		if st.GoalMet {
+			progress = lipgloss.NewStyle().Foreground(ui.SuccessColor).Render(progress + " ✓")
+		}
+
+		line := fmt.Sprintf("%-28s %s", label, progress)
+		if st.Streak > 0 {
+			line += lipgloss.NewStyle().Foreground(ui.WarningColor).Render(fmt.Sprintf("  🔥 %d", st.Streak))
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
+
+func expandStatusTemplate(template string, st topicStatus) string {
+	check := ""
+	if st.GoalMet {
+		check = "✓"
+	}
+	return strings.NewReplacer(
+		"{id}", st.ID,
+		"{name}", st.Name,
+		"{emoji}", st.Emoji,
+		"{done}", strconv.Itoa(st.Today),
+		"{goal}", strconv.Itoa(st.DailyGoal),
+		"{left}", strconv.Itoa(max(st.DailyGoal-st.Today, 0)),
+		"{streak}", strconv.Itoa(st.Streak),
+		"{total}", strconv.Itoa(st.TotalCheckIns),
+		"{check}", check,
+	).Replace(template)
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"att/model"
+)
+
+// Topic definitions live in topics.json in the data repo, next to
+// progress.json, so they sync between machines along with the history.
+// config.json keeps only what belongs to one machine: the data path,
+// remote, badges, webhooks, hooks and quiet hours.
+const topicsFile = "topics.json"
+
+const topicsSchemaVersion = 1
+
+var topicsMigrations []migration
+
+type topicsData struct {
+	// Version is the schema version of topics.json; see schema.go.
+	Version int                           `json:"version"`
+	Topics  map[string]*model.TopicConfig `json:"topics"`
+}
+
+// readTopics returns the topics stored in the data repo at dataPath, or
+// nil without error if it has no topics.json yet.
+func readTopics(dataPath string) (map[string]*model.TopicConfig, error) {
+	path := filepath.Join(dataPath, topicsFile)
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dataErrorf("reading topics: %v", err)
+	}
+
+	f := schemaFile{name: topicsFile, path: path, current: topicsSchemaVersion, migrations: topicsMigrations, errorf: dataErrorf}
+	if raw, _, err = upgradeSchema(f, raw); err != nil {
+		return nil, err
+	}
+	var td topicsData
+	if err := json.Unmarshal(raw, &td); err != nil {
+		return nil, dataErrorf("parsing %s: %v", path, err)
+	}
+	if td.Topics == nil {
+		td.Topics = make(map[string]*model.TopicConfig)
+	}
+	return td.Topics, nil
+}
+
+// stageTopics writes topics.json to dataPath and, once the data repo
+// exists, stages it for the next commit. It reports whether the file
+// changed. Callers hold the data lock.
+func stageTopics(dataPath string, topics map[string]*model.TopicConfig) (bool, error) {
+	if topics == nil {
+		topics = make(map[string]*model.TopicConfig)
+	}
+	raw, err := json.MarshalIndent(topicsData{Version: topicsSchemaVersion, Topics: topics}, "", "  ")
+	if err != nil {
+		return false, dataErrorf("marshaling topics: %v", err)
+	}
+	path := filepath.Join(dataPath, topicsFile)
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, raw) {
+		return false, nil
+	}
+
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return false, dataErrorf("creating data directory: %v", err)
+	}
+	if err := os.WriteFile(path, raw, 0644); err != nil {
+		return false, dataErrorf("writing topics: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataPath, ".git")); err == nil {
+		runGit(dataPath, "add", topicsFile)
+	}
+	return true, nil
+}
+
+// writeTopics writes topics.json to dataPath and commits it on its own
+// with message if it changed. Callers hold the data lock.
+func writeTopics(dataPath string, topics map[string]*model.TopicConfig, message string) error {
+	changed, err := stageTopics(dataPath, topics)
+	if err != nil || !changed {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dataPath, ".git")); err == nil {
+		runGit(dataPath, "commit", "-m", message, "--", topicsFile)
+	}
+	return nil
+}
+
+// saveTopics stores cfg's topics in its data repo as they are, for
+// setting up a repo or repairing it. They reach other machines with the
+// next sync; edits go through updateTopics.
+func saveTopics(cfg *model.Config) error {
+	if cfg.DataPath == "" {
+		return nil
+	}
+	unlock, err := lockData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeTopics(cfg.DataPath, cfg.Topics, "Topics: update")
+}
+
+// updateTopics applies change to cfg's topics and commits them with
+// message. It pulls and re-reads the topics first, under the data lock, so
+// the change builds on other machines' edits instead of overwriting them.
+func updateTopics(cfg *model.Config, message string, change func(topics map[string]*model.TopicConfig) error) error {
+	unlock, err := lockData(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
+	}
+	if err := refreshTopics(cfg); err != nil {
+		return err
+	}
+	if err := change(cfg.Topics); err != nil {
+		return err
+	}
+	if err := writeTopics(cfg.DataPath, cfg.Topics, message); err != nil {
+		return err
+	}
+	if cfg.SSHURL != "" {
+		syncRepo(cfg)
+	}
+	return nil
+}
+
+// refreshTopics re-reads cfg's topics from its data repo, which a pull
+// may have changed since the config was loaded. Callers hold the data lock.
+func refreshTopics(cfg *model.Config) error {
+	topics, err := readTopics(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	if topics != nil {
+		cfg.Topics = topics
+	}
+	return nil
+}
+
+// moveTopicsToDataRepo is the config.json migration from schema version
+// 1 to 2. Topics already in the data repo, synced from another machine,
+// win; topics only this machine knew about are added to them.
+func moveTopicsToDataRepo(doc map[string]any) error {
+	raw, err := json.Marshal(doc["topics"])
+	if err != nil {
+		return err
+	}
+	var local map[string]*model.TopicConfig
+	if err := json.Unmarshal(raw, &local); err != nil {
+		return err
+	}
+	delete(doc, "topics")
+	if len(local) == 0 {
+		return nil
+	}
+
+	dataPath := dataOverride()
+	if dataPath == "" {
+		p, _ := doc["data_path"].(string)
+		dataPath = expandHome(p)
+	}
+	if dataPath == "" {
+		return fmt.Errorf("no data_path to move %d topics to", len(local))
+	}
+	unlock, err := lockData(dataPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	synced, err := readTopics(dataPath)
+	if err != nil {
+		return err
+	}
+	if synced == nil {
+		synced = local
+	} else {
+		for id, t := range local {
+			if _, ok := synced[id]; !ok {
+				synced[id] = t
+			}
+		}
+	}
+	if err := writeTopics(dataPath, synced, "Move topic definitions into the data repo"); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Note: topics are now kept in %s so they sync with your data\n", filepath.Join(dataPath, topicsFile))
+	return nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"att/model"
+)
+
+func TestTopicsRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	if topics, err := readTopics(dir); err != nil || topics != nil {
+		t.Fatalf("readTopics on an empty repo = %v, %v; want nil, nil", topics, err)
+	}
+
+	want := map[string]*model.TopicConfig{
+		"dsa":     {Name: "DSA", DailyGoal: 3, Emoji: "💻", Enabled: true, Reminders: []string{"09:00", "18:30"}},
+		"reading": {Name: "Reading", DailyGoal: 1, Emoji: "📚"},
+	}
+	changed, err := stageTopics(dir, want)
+	if err != nil || !changed {
+		t.Fatalf("stageTopics = %v, %v; want true, nil", changed, err)
+	}
+	got, err := readTopics(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readTopics = %+v, want %+v", got, want)
+	}
+
+	if changed, err := stageTopics(dir, got); err != nil || changed {
+		t.Errorf("stageTopics with the same topics = %v, %v; want false, nil", changed, err)
+	}
+}
+
+func TestReadTopicsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"newer schema", `{"version": 99, "topics": {}}`},
+		{"invalid JSON", `{"version": 1, "topics": [`},
+		{"wrong type", `{"version": 1, "topics": []}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, topicsFile), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := readTopics(dir); exitCode(err) != exitData {
+				t.Errorf("readTopics error = %v, want a data error", err)
+			}
+		})
+	}
+}
+
+func TestMoveTopicsToDataRepo(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string         // config.json at schema version 1; DATA is the data path
+		synced string         // topics.json already in the data repo, if any
+		want   map[string]any // topics.json afterwards; nil if it must not exist
+	}{
+		{
+			name:   "moves topics",
+			config: `{"version": 1, "data_path": "DATA", "topics": {"dsa": {"name": "DSA", "daily_goal": 2, "emoji": "💻", "enabled": true}}}`,
+			want:   map[string]any{"dsa": "DSA"},
+		},
+		{
+			name:   "synced topics win",
+			config: `{"version": 1, "data_path": "DATA", "topics": {"dsa": {"name": "Local DSA"}, "gym": {"name": "Gym"}}}`,
+			synced: `{"version": 1, "topics": {"dsa": {"name": "Synced DSA"}, "read": {"name": "Read"}}}`,
+			want:   map[string]any{"dsa": "Synced DSA", "gym": "Gym", "read": "Read"},
+		},
+		{
+			name:   "no topics",
+			config: `{"version": 1, "data_path": "DATA", "topics": {}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ATT_DATA", "")
+			dataPath := t.TempDir()
+			if tt.synced != "" {
+				if err := os.WriteFile(filepath.Join(dataPath, topicsFile), []byte(tt.synced), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			doc := decodeConfig(t, tt.config, dataPath)
+
+			if err := moveTopicsToDataRepo(doc); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := doc["topics"]; ok {
+				t.Error("config still has topics after the migration")
+			}
+			checkTopicNames(t, dataPath, tt.want)
+		})
+	}
+}
+
+func TestMoveTopicsToDataRepoHomePath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ATT_DATA", "")
+	doc := decodeConfig(t, `{"version": 1, "data_path": "~/.att", "topics": {"dsa": {"name": "DSA"}}}`, "")
+
+	if err := moveTopicsToDataRepo(doc); err != nil {
+		t.Fatal(err)
+	}
+	checkTopicNames(t, filepath.Join(home, ".att"), map[string]any{"dsa": "DSA"})
+}
+
+func TestMoveTopicsToDataRepoOverride(t *testing.T) {
+	override := t.TempDir()
+	t.Setenv("ATT_DATA", override)
+	configured := t.TempDir()
+	doc := decodeConfig(t, `{"version": 1, "data_path": "DATA", "topics": {"dsa": {"name": "DSA"}}}`, configured)
+
+	if err := moveTopicsToDataRepo(doc); err != nil {
+		t.Fatal(err)
+	}
+	checkTopicNames(t, override, map[string]any{"dsa": "DSA"})
+	checkTopicNames(t, configured, nil)
+}
+
+// decodeConfig decodes config as migrations see it, with DATA replaced by
+// dataPath.
+func decodeConfig(t *testing.T, config, dataPath string) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(config), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["data_path"] == "DATA" {
+		doc["data_path"] = dataPath
+	}
+	return doc
+}
+
+// checkTopicNames checks the names of the topics in dataPath's topics.json;
+// want nil means the file must not exist.
+func checkTopicNames(t *testing.T, dataPath string, want map[string]any) {
+	t.Helper()
+	topics, err := readTopics(dataPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want == nil {
+		if topics != nil {
+			t.Errorf("%s has topics %v, want none", dataPath, topics)
+		}
+		return
+	}
+	got := make(map[string]any)
+	for id, tc := range topics {
+		got[id] = tc.Name
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("topics in %s = %v, want %v", dataPath, got, want)
+	}
+}
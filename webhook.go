@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"att/model"
+)
+
+// Webhook deliveries are queued in an outbox inside the data repo's .git
+// directory, so they are never committed, and sent by a detached
+// `att webhook flush` so a slow or unreachable endpoint never holds up a
+// check-in. Failed deliveries are retried with exponential backoff.
+const (
+	webhookTimeout     = 10 * time.Second
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+)
+
+type outboxItem struct {
+	ID        string          `json:"id"`
+	URL       string          `json:"url"`
+	Event     string          `json:"event"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	NextTry   time.Time       `json:"next_try"`
+	LastError string          `json:"last_error,omitempty"`
+}
+
+func outboxDir(dataPath string) string {
+	return filepath.Join(dataPath, ".git", "att-outbox")
+}
+
+func newWebhookCommand() *command {
+	webhookCmd := newCommand("webhook", "", "Manage outgoing webhooks")
+
+	addCmd := newCommand("add", "<url>", "Send events to a URL")
+	addCmd.minArgs, addCmd.maxArgs = 1, 1
+	secret := addCmd.flags.String("secret", "", "sign payloads with HMAC-SHA256 using `key`")
+	events := addCmd.flags.String("events", "", "comma-separated `events` to send (default: all of "+strings.Join(eventNames, ", ")+")")
+	addCmd.examples = []string{
+		"att webhook add https://relay.example/att --secret s3cret",
+		"att webhook add https://relay.example/goals --events goal_met,streak_milestone",
+	}
+	addCmd.run = func(args []string) error { return webhookAdd(args[0], *secret, *events) }
+
+	listCmd := newCommand("list", "", "List webhooks and pending deliveries")
+	listCmd.aliases = []string{"ls"}
+	listCmd.maxArgs = 0
+	listCmd.run = func([]string) error { return webhookList() }
+
+	removeCmd := newCommand("remove", "<url>", "Stop sending events to a URL")
+	removeCmd.aliases = []string{"rm"}
+	removeCmd.minArgs, removeCmd.maxArgs = 1, 1
+	removeCmd.run = func(args []string) error { return webhookRemove(args[0]) }
+
+	testCmd := newCommand("test", "[url]", "Send a test event now and report the response")
+	testCmd.maxArgs = 1
+	testCmd.run = func(args []string) error { return webhookTest(args) }
+
+	flushCmd := newCommand("flush", "", "Retry queued deliveries now")
+	flushCmd.maxArgs = 0
+	quiet := flushCmd.flags.Bool("quiet", false, "print nothing")
+	// By hand, everything is retried now; the background flush started after
+	// a check-in passes --quiet and respects each delivery's backoff.
+	flushCmd.run = func([]string) error { return webhookFlush(*quiet, !*quiet) }
+
+	webhookCmd.add(addCmd, listCmd, removeCmd, testCmd, flushCmd)
+	return webhookCmd
+}
+
+func webhookAdd(url, secret, events string) error {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return usageErrorf("webhook URL must start with http:// or https://")
+	}
+	var names []string
+	for _, e := range strings.Split(events, ",") {
+		if e = canonicalEvent(strings.TrimSpace(e)); e == "" {
+			continue
+		}
+		if !slices.Contains(eventNames, e) {
+			return usageErrorf("unknown event %q (want %s)", e, strings.Join(eventNames, ", "))
+		}
+		names = append(names, e)
+	}
+
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	for _, wh := range cfg.Webhooks {
+		if wh.URL == url {
+			return usageErrorf("webhook %s already exists", url)
+		}
+	}
+	cfg.Webhooks = append(cfg.Webhooks, &model.Webhook{URL: url, Secret: secret, Events: names})
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Webhook added: %s\n", url)
+	return nil
+}
+
+func webhookList() error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Webhooks) == 0 {
+		fmt.Println("No webhooks configured. Add one with: att webhook add <url>")
+		return nil
+	}
+	pending := make(map[string]int)
+	items, _ := readOutbox(outboxDir(cfg.DataPath))
+	for _, it := range items {
+		pending[it.URL]++
+	}
+	for _, wh := range cfg.Webhooks {
+		events := "all events"
+		if len(wh.Events) > 0 {
+			events = strings.Join(wh.Events, ", ")
+		}
+		signed := ""
+		if wh.Secret != "" {
+			signed = ", signed"
+		}
+		fmt.Printf("%s (%s%s)\n", wh.URL, events, signed)
+		if n := pending[wh.URL]; n > 0 {
+			fmt.Printf("  %d deliveries pending\n", n)
+		}
+	}
+	return nil
+}
+
+func webhookRemove(url string) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(cfg.Webhooks, func(wh *model.Webhook) bool { return wh.URL == url })
+	if i < 0 {
+		return notFoundErrorf("no webhook for %s", url)
+	}
+	cfg.Webhooks = slices.Delete(cfg.Webhooks, i, i+1)
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Webhook removed: %s\n", url)
+	return nil
+}
+
+func webhookTest(args []string) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	targets := cfg.Webhooks
+	if len(args) > 0 {
+		i := slices.IndexFunc(cfg.Webhooks, func(wh *model.Webhook) bool { return wh.URL == args[0] })
+		if i < 0 {
+			return notFoundErrorf("no webhook for %s", args[0])
+		}
+		targets = cfg.Webhooks[i : i+1]
+	}
+	if len(targets) == 0 {
+		return configErrorf("no webhooks configured")
+	}
+
+	payload, _ := json.Marshal(map[string]string{"event": "test", "time": time.Now().Format(time.RFC3339)})
+	failed := 0
+	for _, wh := range targets {
+		if err := deliverWebhook(wh, newDeliveryID(), "test", payload); err != nil {
+			fmt.Printf("✗ %s: %v\n", wh.URL, err)
+			failed++
+		} else {
+			fmt.Printf("✓ %s\n", wh.URL)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d webhooks failed", failed, len(targets))
+	}
+	return nil
+}
+
+// notifyWebhooks queues events for every subscribed webhook and starts a
+// background flush. Problems are reported but never fail the caller.
+func notifyWebhooks(cfg *model.Config, events []attEvent) {
+	if len(cfg.Webhooks) == 0 || len(events) == 0 {
+		return
+	}
+	dir := outboxDir(cfg.DataPath)
+	queued := 0
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			continue
+		}
+		for _, wh := range cfg.Webhooks {
+			if len(wh.Events) > 0 && !slices.ContainsFunc(wh.Events, func(name string) bool { return canonicalEvent(name) == e.Event }) {
+				continue
+			}
+			item := outboxItem{ID: newDeliveryID(), URL: wh.URL, Event: e.Event, Payload: payload, NextTry: time.Now()}
+			if err := writeOutboxItem(dir, item); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: queueing webhook: %v\n", err)
+				continue
+			}
+			queued++
+		}
+	}
+	if queued > 0 {
+		startBackgroundFlush()
+	}
+}
+
+// startBackgroundFlush runs `att webhook flush --quiet` detached from this
+// process and its terminal.
+func startBackgroundFlush() {
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+	cmd := exec.Command(exe, append(attArgs(), "webhook", "flush", "--quiet")...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err == nil {
+		cmd.Process.Release()
+	}
+}
+
+// webhookFlush delivers queued events that are due. Only one flush runs at
+// a time; a second one exits immediately.
+func webhookFlush(quiet, force bool) error {
+	cfg, err := requireConfig()
+	if err != nil {
+		return err
+	}
+	dir := outboxDir(cfg.DataPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return dataErrorf("creating outbox: %v", err)
+	}
+	lock, err := os.OpenFile(filepath.Join(dir, ".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return dataErrorf("opening outbox lock: %v", err)
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		return nil // another flush is running
+	}
+
+	// Keep going until a pass finds nothing new, so deliveries queued while
+	// this flush was running are not left for the next one.
+	sent, retrying, dropped := 0, 0, 0
+	tried := make(map[string]bool)
+	for {
+		items, err := readOutbox(dir)
+		if err != nil {
+			return err
+		}
+		progress := false
+		retrying = 0
+		now := time.Now()
+		for _, it := range items {
+			if tried[it.ID] || !force && it.NextTry.After(now) {
+				retrying++
+				continue
+			}
+			tried[it.ID] = true
+			progress = true
+			i := slices.IndexFunc(cfg.Webhooks, func(wh *model.Webhook) bool { return wh.URL == it.URL })
+			if i < 0 {
+				os.Remove(filepath.Join(dir, it.ID+".json")) // webhook was removed
+				continue
+			}
+			err := deliverWebhook(cfg.Webhooks[i], it.ID, it.Event, it.Payload)
+			if err == nil {
+				os.Remove(filepath.Join(dir, it.ID+".json"))
+				sent++
+				continue
+			}
+
+			it.Attempts++
+			it.LastError = err.Error()
+			if it.Attempts >= webhookMaxAttempts {
+				if writeOutboxItem(filepath.Join(dir, "failed"), it) == nil {
+					os.Remove(filepath.Join(dir, it.ID+".json"))
+				}
+				dropped++
+				continue
+			}
+			backoff := min(webhookBaseBackoff<<(it.Attempts-1), webhookMaxBackoff)
+			it.NextTry = time.Now().Add(backoff)
+			writeOutboxItem(dir, it)
+			retrying++
+		}
+		if !progress {
+			break
+		}
+	}
+
+	if !quiet {
+		fmt.Printf("✓ %d delivered, %d waiting to retry", sent, retrying)
+		if dropped > 0 {
+			fmt.Printf(", %d given up (kept in %s)", dropped, filepath.Join(dir, "failed"))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// deliverWebhook POSTs payload to the webhook. Deliveries carry the event
+// name and a unique ID so receivers can deduplicate retries, and an
+// HMAC-SHA256 signature of the body when the webhook has a secret.
+func deliverWebhook(wh *model.Webhook, id, event string, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, wh.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "att/"+version)
+	req.Header.Set("X-Att-Event", event)
+	req.Header.Set("X-Att-Delivery", id)
+	if wh.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(wh.Secret))
+		mac.Write(payload)
+		req.Header.Set("X-Att-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	// A time prefix keeps the outbox in delivery order.
+	return strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + hex.EncodeToString(b)
+}
+
+func writeOutboxItem(dir string, it outboxItem) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(it)
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(dir, it.ID+".tmp")
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, it.ID+".json"))
+}
+
+func readOutbox(dir string) ([]outboxItem, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, dataErrorf("reading outbox: %v", err)
+	}
+	var items []outboxItem
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var it outboxItem
+		if json.Unmarshal(raw, &it) == nil {
+			items = append(items, it)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items, nil
+}